@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// AssembleMessage reads the whole stream and returns it as a single WRP
+// message, so handlers that expect one message can consume streamed content.
+// The payload is the reassembled stream.  The other fields are taken from the
+// first packet of the stream, with the SSP headers removed.
+//
+// Every packet must have the same Source, Destination, ContentType, Metadata,
+// PartnerIDs and non-SSP headers, otherwise an error wrapping
+// ErrInconsistentEnvelope is returned.  The TransactionUUID is not compared
+// since it may be updated for each packet.
+//
+// AssembleMessage blocks until the stream ends or the context is canceled.  It
+// must not be used after data has been read with Read.
+func (a *Assembler) AssembleMessage(ctx context.Context) (*wrp.Message, error) {
+	a.init()
+
+	a.m.Lock()
+	started := a.started
+	a.m.Unlock()
+
+	if started {
+		return nil, fmt.Errorf("%w: part of the stream has already been read", ErrNotAvailable)
+	}
+
+	var payload bytes.Buffer
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := a.readContext(ctx, buf)
+		payload.Write(buf[:n])
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if a.envelopeErr != nil {
+		return nil, a.envelopeErr
+	}
+
+	// The Assembler was closed before any packets were read.
+	if a.envelope == nil {
+		return nil, ErrNotAvailable
+	}
+
+	msg := a.envelope.Message
+	msg.Payload = payload.Bytes()
+
+	return &msg, nil
+}
+
+// checkEnvelope compares the envelope of each packet buffered with the
+// envelope kept, and keeps the envelope of the lowest numbered packet for
+// AssembleMessage.  Only the first difference is kept.  Must be called with the
+// lock held.
+func (a *Assembler) checkEnvelope(packet *simpleStreamingMessage) {
+	if a.envelope != nil && a.envelopeErr == nil {
+		if field := envelopeDiff(&a.envelope.Message, &packet.Message); field != "" {
+			a.envelopeErr = &inconsistentEnvelope{
+				number: packet.StreamPacketNumber,
+				field:  field,
+			}
+		}
+	}
+
+	if a.envelope == nil || packet.StreamPacketNumber < a.envelope.StreamPacketNumber {
+		envelope := *packet
+		envelope.Payload = nil
+		a.envelope = &envelope
+	}
+}
+
+// envelopeDiff returns the name of the first field compared that differs
+// between the messages, or an empty string if they match.
+func envelopeDiff(a, b *wrp.Message) string {
+	switch {
+	case a.Source != b.Source:
+		return "Source"
+	case a.Destination != b.Destination:
+		return "Destination"
+	case a.ContentType != b.ContentType:
+		return "ContentType"
+	case !maps.Equal(a.Metadata, b.Metadata):
+		return "Metadata"
+	case !slices.Equal(a.PartnerIDs, b.PartnerIDs):
+		return "PartnerIDs"
+	case !slices.Equal(a.Headers, b.Headers):
+		return "Headers"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestAssembler_AssembleMessage(t *testing.T) {
+	in := wrp.Message{
+		Type:            wrp.SimpleEventMessageType,
+		Source:          "mac:112233445566",
+		Destination:     "event:logs/mac:112233445566",
+		TransactionUUID: "tx-1",
+		ContentType:     "text/plain",
+		Headers:         []string{"app: upload"},
+		Metadata:        map[string]string{"/boot-time": "1234"},
+		PartnerIDs:      []string{"comcast"},
+		SessionID:       "session",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(n int, msg *wrp.Message)
+		reverse bool
+		field   string
+	}{
+		{
+			name: "envelope is preserved",
+		}, {
+			name: "transaction UUID may differ",
+			modify: func(n int, msg *wrp.Message) {
+				if n > 0 {
+					msg.TransactionUUID = "tx-other"
+				}
+			},
+		}, {
+			name: "source differs",
+			modify: func(n int, msg *wrp.Message) {
+				if n == 2 {
+					msg.Source = "mac:665544332211"
+				}
+			},
+			field: "Source",
+		}, {
+			name: "metadata differs",
+			modify: func(n int, msg *wrp.Message) {
+				if n == 1 {
+					msg.Metadata = map[string]string{"/boot-time": "5678"}
+				}
+			},
+			field: "Metadata",
+		}, {
+			name: "headers differ",
+			modify: func(n int, msg *wrp.Message) {
+				if n == 1 {
+					msg.Headers = append(msg.Headers, "extra: header")
+				}
+			},
+			field: "Headers",
+		}, {
+			name: "out of order keeps the first packet",
+			modify: func(n int, msg *wrp.Message) {
+				if n > 0 {
+					msg.TransactionUUID = "tx-other"
+				}
+			},
+			reverse: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packetizer, err := New(
+				ID("123"),
+				Reader(strings.NewReader("Hello, World!")),
+				MaxPacketSize(5),
+			)
+			require.NoError(t, err)
+
+			var packets []wrp.Message
+			for packet, err := range packetizer.Packets(context.Background(), in) {
+				require.NoError(t, err)
+				if tt.modify != nil {
+					tt.modify(len(packets), packet)
+				}
+				packets = append(packets, *packet)
+			}
+			if tt.reverse {
+				slices.Reverse(packets)
+			}
+
+			var assembler Assembler
+			for _, packet := range packets {
+				require.NoError(t, assembler.ProcessWRP(context.Background(), packet))
+			}
+
+			got, err := assembler.AssembleMessage(context.Background())
+			if tt.field != "" {
+				assert.ErrorIs(t, err, ErrInconsistentEnvelope)
+				assert.ErrorContains(t, err, tt.field)
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			want := in
+			want.Payload = []byte("Hello, World!")
+			assert.Equal(t, want, *got)
+		})
+	}
+}
+
+func TestAssembler_AssembleMessageErrors(t *testing.T) {
+	packet := func(n string, payload string, headers ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers:     append([]string{"stream-id: 1", "stream-packet-number: " + n}, headers...),
+			Payload:     []byte(payload),
+		}
+	}
+
+	t.Run("context canceled", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		got, err := assembler.AssembleMessage(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, got)
+	})
+
+	t.Run("stream ended early", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello", "stream-final-packet: timeout")))
+
+		got, err := assembler.AssembleMessage(context.Background())
+		assert.ErrorIs(t, err, ErrStreamTimeout)
+		assert.Nil(t, got)
+	})
+
+	t.Run("closed without packets", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.Close())
+
+		got, err := assembler.AssembleMessage(context.Background())
+		assert.ErrorIs(t, err, ErrNotAvailable)
+		assert.Nil(t, got)
+	})
+
+	t.Run("empty packet already read", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("1", "World", "stream-final-packet: eof")))
+
+		chunk, err := assembler.NextPacket(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, chunk.Data)
+
+		got, err := assembler.AssembleMessage(context.Background())
+		assert.ErrorIs(t, err, ErrNotAvailable)
+		assert.Nil(t, got)
+	})
+
+	t.Run("already read", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("1", "World", "stream-final-packet: eof")))
+
+		_, err := io.ReadFull(&assembler, make([]byte, 2))
+		require.NoError(t, err)
+
+		got, err := assembler.AssembleMessage(context.Background())
+		assert.ErrorIs(t, err, ErrNotAvailable)
+		assert.Nil(t, got)
+	})
+}
@@ -5,9 +5,14 @@ package wrpssp
 
 import (
 	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/xmidt-org/wrp-go/v5"
 )
@@ -22,20 +27,114 @@ import (
 // multiple writers. A single goroutine should call Read(), while one or more
 // goroutines may call ProcessWRP() to deliver packets. Multiple concurrent Read()
 // calls are not supported and will result in undefined behavior.
+//
+// The Assembler may be created with NewAssembler, or used as a zero value
+// configured by setting the exported fields before it is used.
 type Assembler struct {
 	Validators []wrp.Processor
 	// Maximum allowed gap between current and received packet number (0 = unlimited)
 	MaxPacketGap int
+	// Maximum number of packets, and payload bytes as received, buffered
+	// waiting to be read (0 = unlimited).  Packets over the limit are rejected
+	// with ErrBufferFull, except for the next packet to be read.
+	MaxBufferedPackets int
+	MaxBufferedBytes   int
+	// IdleTimeout ends the stream with an error matching ErrStreamTimeout
+	// when Read waits this long without any packet being received
+	// (0 = unlimited).
+	IdleTimeout time.Duration
+	// StreamID is the stream to assemble.  When set, packets of other streams
+	// are rejected with wrp.ErrNotHandled.
+	StreamID string
+	// Keys provides the keys to decrypt packets encrypted by a Packetizer using
+	// WithEncryption.  When set, every packet must be encrypted.  Packets are
+	// authenticated before they are buffered, and those that fail are
+	// rejected with the error.
+	Keys KeyProvider
+	// Verifier verifies the stream-signature of each packet before it is
+	// buffered.  When set, every packet must be signed.
+	Verifier Verifier
+	// OnProgress is called with the progress of the stream as packets are
+	// received and read, at most once per ProgressInterval, and once when the
+	// stream ends.  It is called from the goroutines calling Read and
+	// ProcessWRP, without the lock held.
+	OnProgress       func(Progress)
+	ProgressInterval time.Duration
+	// Observer receives the events of the stream.  It is called without the
+	// lock held.
+	Observer Observer
+	// StrictDuplicates compares packets received more than once with the
+	// first copy, and rejects those that differ with ErrConflictingDuplicate
+	// instead of dropping them.  Packets already read are compared using a
+	// digest kept for the last DuplicateHistory packets (0 = 64).
+	StrictDuplicates bool
+	DuplicateHistory int
+	// Strict ends the stream with a ProtocolViolationError when a packet
+	// breaks the rules of the protocol: more than one final packet, a packet
+	// numbered after the final packet, or a stream-estimated-total-length that
+	// is less than the estimate of an earlier packet.  The estimate may grow,
+	// as it does with RefreshEstimatedLength.  The error is returned by
+	// ProcessWRP and by Read.  Without it, the first final packet read ends
+	// the stream and the other packets are dropped.
+	Strict bool
+	// StrictHeaders checks the headers of each packet against the grammar in
+	// protocol.md, as ValidateHeaders does, and rejects packets that do not
+	// conform.  Without it, the headers are parsed leniently.
+	StrictHeaders bool
 
 	closed  bool
 	current int64
 	final   error
 	offset  int
+	length  uint64 // Decoded bytes of the packets fully read
 	m       sync.Mutex
 	decoded *decoded
 
+	// The trailers of the final packet, once it has been read.
+	trailers map[string]string
+
+	// The metadata of the first packet received that has any.
+	metadata *StreamMetadata
+
+	// The progress of the stream, and if Read has reached the end.
+	progress progressTracker
+	done     bool
+	events   []Event // Waiting to be sent to the Observer
+
+	// The duplicates dropped, and the payload digests of the packets read
+	// when StrictDuplicates is set.
+	duplicates DuplicateStats
+	digests    map[int64][sha256.Size]byte
+
+	// The final packet and the estimated length of the highest numbered packet
+	// received with one, for Strict.
+	finalSeen   bool
+	finalNumber int64
+	estimated   struct {
+		length uint64
+		number int64
+	}
+
+	// The envelope of the lowest numbered packet received, and the first
+	// packet received with an envelope that differs from it.
+	envelope    *simpleStreamingMessage
+	envelopeErr error
+
+	// If any of the stream has been read.
+	started bool
+
+	// The highest packet number received, for the stream-total-packets check.
+	highest int64
+
+	// The stream ID and addresses of the first packet received.
+	streamID    string
+	source      string
+	destination string
+
 	once    sync.Once
+	aeads   map[string]cipher.AEAD
 	packets map[int64]*simpleStreamingMessage
+	bytes   int           // Payload bytes of the packets
 	event   chan struct{} // Signals when data arrives or close occurs
 }
 
@@ -57,6 +156,12 @@ func (a *Assembler) init() {
 
 // Read implements an io.Reader method.
 func (a *Assembler) Read(p []byte) (int, error) {
+	return a.readContext(context.Background(), p)
+}
+
+// readContext is Read, but waiting for more packets stops when the context is
+// canceled.
+func (a *Assembler) readContext(ctx context.Context, p []byte) (int, error) {
 	a.init()
 
 	// Per io.Reader contract, zero-length reads must return immediately
@@ -68,8 +173,11 @@ func (a *Assembler) Read(p []byte) (int, error) {
 	for {
 		a.m.Lock()
 		n, err := a.read(p[offset:])
+		report := a.consumed(n, err)
 		a.m.Unlock()
 
+		report()
+
 		offset += n
 
 		// Return if we hit an error or EOF
@@ -88,10 +196,59 @@ func (a *Assembler) Read(p []byte) (int, error) {
 		}
 
 		// Wait for more data
-		<-a.event
+		if err := a.wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// consumed records that n bytes were read and if the stream ended with err.  It
+// returns a function that sends the events and progress, which must be called
+// once the lock is released.  Must be called with the lock held.
+func (a *Assembler) consumed(n int, err error) func() {
+	a.progress.bytes += uint64(n) // nolint:gosec
+	if err != nil && !a.done {
+		a.done = true
+		a.progress.complete = errors.Is(err, io.EOF)
+		a.observeEnd(err)
+	}
+	progress, notify := a.progressDue()
+	events := a.takeEvents()
+
+	return func() {
+		a.dispatch(events)
+		if notify {
+			a.OnProgress(progress)
+		}
 	}
 }
 
+// wait blocks until a packet arrives or the Assembler is closed.  If the
+// IdleTimeout passes first, the stream ends with an error, which is returned by
+// the next read.
+func (a *Assembler) wait(ctx context.Context) error {
+	var expired <-chan time.Time
+	if a.IdleTimeout > 0 {
+		timer := time.NewTimer(a.IdleTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-a.event:
+	case <-expired:
+		a.m.Lock()
+		if !a.closed {
+			a.fail(&idleTimeout{timeout: a.IdleTimeout})
+		}
+		a.m.Unlock()
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+
+	return nil
+}
+
 // read attempts to read from the current packet. Must be called with lock held.
 // Returns data if available, or (0, nil) if we need to wait for more packets.
 func (a *Assembler) read(p []byte) (int, error) {
@@ -104,6 +261,12 @@ func (a *Assembler) read(p []byte) (int, error) {
 	packet, buf, err := a.getPacket(a.current)
 	if err != nil {
 		// Decoding error - close and return
+		a.observe(Event{
+			Kind:         EventDecodeFailed,
+			StreamID:     a.streamID,
+			PacketNumber: a.current,
+			Err:          err,
+		})
 		a.final = err
 		a.close()
 		return 0, err
@@ -135,25 +298,38 @@ func (a *Assembler) processPacket(packet *simpleStreamingMessage, buf []byte, p
 		if strings.ToLower(finalMsg) == "eof" {
 			a.final = io.EOF
 		} else {
-			a.final = newUnexpectedEOF(finalMsg)
+			a.final = &StreamError{Reason: ParseReason(finalMsg)}
+		}
+		if err := a.checkSummary(packet, buf); err != nil {
+			a.final = err
 		}
 		a.close()
 
 		// Drop any packets after the final packet
 		for num := range a.packets {
 			if num > a.current {
-				delete(a.packets, num)
+				a.remove(num)
 			}
 		}
 	}
 
+	a.started = true
+
 	// Copy available data
 	n := copy(p, buf[a.offset:])
 	a.offset += n
 
 	// Advance to next packet if this one is fully consumed
 	if a.offset >= len(buf) {
-		delete(a.packets, a.current)
+		if packet.StreamFinalPacket != "" {
+			a.trailers = packet.StreamTrailers
+			if a.trailers == nil {
+				a.trailers = map[string]string{}
+			}
+		}
+		a.keepDigest(packet)
+		a.remove(a.current)
+		a.length += uint64(len(buf))
 		a.current++
 		a.decoded = nil
 		a.offset = 0
@@ -162,6 +338,79 @@ func (a *Assembler) processPacket(packet *simpleStreamingMessage, buf []byte, p
 	return n
 }
 
+// checkSummary compares the stream-total-packets and stream-total-length of the
+// final packet with the stream received.  Final packets without the summary
+// headers are not checked.
+//
+// The check runs when the final packet is read, so every packet before it has
+// been read.  The packet count is compared with the highest packet number
+// received so far, which detects packets numbered after the final packet that
+// arrived before it was read; packets arriving later are rejected since the
+// stream is closed.  The length detects payloads that were truncated or
+// padded.
+func (a *Assembler) checkSummary(packet *simpleStreamingMessage, buf []byte) error {
+	if packet.StreamTotalPackets == 0 {
+		return nil
+	}
+
+	if received := max(a.current, a.highest) + 1; packet.StreamTotalPackets != received {
+		return &SummaryMismatchError{
+			Header:   stream_total_packets,
+			Expected: uint64(packet.StreamTotalPackets),
+			Actual:   uint64(received),
+		}
+	}
+
+	if length := a.length + uint64(len(buf)); packet.StreamTotalLength != length {
+		return &SummaryMismatchError{
+			Header:   stream_total_length,
+			Expected: packet.StreamTotalLength,
+			Actual:   length,
+		}
+	}
+
+	return nil
+}
+
+// observeEnd queues the event for the end of the stream being read.  Must be
+// called with the lock held.
+func (a *Assembler) observeEnd(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	a.observe(streamEndEvent(a.streamID, max(a.current-1, 0), err))
+}
+
+// complete reports if every packet up to and including the final packet has
+// been received, so reading the rest of the stream does not block.
+func (a *Assembler) complete() bool {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if a.closed {
+		return true
+	}
+
+	final := int64(-1)
+	for num, packet := range a.packets {
+		if packet.StreamFinalPacket != "" {
+			final = num
+			break
+		}
+	}
+	if final < 0 {
+		return false
+	}
+
+	for num := a.current; num <= final; num++ {
+		if _, found := a.packets[num]; !found {
+			return false
+		}
+	}
+
+	return true
+}
+
 // checkStreamEnd determines if the stream has ended.
 // Returns (true, error) if stream is complete, (false, nil) if more data may arrive.
 func (a *Assembler) checkStreamEnd() (bool, error) {
@@ -187,7 +436,7 @@ func (a *Assembler) checkStreamEnd() (bool, error) {
 	}
 
 	// Clear any unreachable buffered packets (due to gaps)
-	clear(a.packets)
+	a.removeAll()
 	a.decoded = nil
 
 	if a.final != nil {
@@ -226,6 +475,40 @@ func (a *Assembler) getPacket(n int64) (*simpleStreamingMessage, []byte, error)
 	return msg, a.decoded.data, nil
 }
 
+// decrypt authenticates the packet and replaces the payload with the plaintext
+// if it is encrypted.  If Keys is set, packets that are not encrypted are
+// rejected.  Must be called with the lock held.
+func (a *Assembler) decrypt(msg *simpleStreamingMessage) error {
+	if msg.StreamKeyID == "" {
+		if a.Keys != nil {
+			return &authenticationFailed{number: msg.StreamPacketNumber}
+		}
+		return nil
+	}
+
+	aead, found := a.aeads[msg.StreamKeyID]
+	if !found {
+		var err error
+		aead, err = newAEAD(a.Keys, msg.StreamKeyID)
+		if err != nil {
+			return err
+		}
+
+		if a.aeads == nil {
+			a.aeads = make(map[string]cipher.AEAD)
+		}
+		a.aeads[msg.StreamKeyID] = aead
+	}
+
+	payload, err := open(aead, msg.StreamKeyID, msg.StreamID, msg.StreamPacketNumber, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	msg.Payload = payload
+	return nil
+}
+
 // Close closes the Assembler and implements the io.Closer interface.
 func (a *Assembler) Close() error {
 	a.init()
@@ -238,6 +521,16 @@ func (a *Assembler) Close() error {
 	return nil
 }
 
+// fail ends the stream with err, dropping the packets that have not been read.
+// Must be called with the lock held.
+func (a *Assembler) fail(err error) {
+	a.final = err
+	a.removeAll()
+	a.decoded = nil
+	a.offset = 0
+	a.close()
+}
+
 func (a *Assembler) close() {
 	if !a.closed {
 		a.closed = true
@@ -255,24 +548,77 @@ func (a *Assembler) ProcessWRP(_ context.Context, msg wrp.Message) error {
 	}
 
 	var ssp simpleStreamingMessage
-	if err := ssp.From(&msg, a.Validators...); err != nil {
+	if err := ssp.parse(&msg, a.StrictHeaders, a.Validators...); err != nil {
 		return err
 	}
 
+	if a.Verifier != nil {
+		if err := ssp.verify(a.Verifier); err != nil {
+			return err
+		}
+	}
+
 	a.m.Lock()
-	defer a.m.Unlock()
+	err := a.buffer(&ssp)
+	progress, notify := a.progressDue()
+	events := a.takeEvents()
+	a.m.Unlock()
+
+	a.dispatch(events)
+	if notify {
+		a.OnProgress(progress)
+	}
+
+	return err
+}
+
+// buffer adds the packet to the packets waiting to be read.  Must be called
+// with the lock held.
+func (a *Assembler) buffer(ssp *simpleStreamingMessage) error {
+	if a.StreamID != "" && ssp.StreamID != a.StreamID {
+		return fmt.Errorf("%w: stream id %q is not %q", wrp.ErrNotHandled, ssp.StreamID, a.StreamID)
+	}
 
 	if a.closed {
 		return ErrClosed
 	}
 
+	// The event is made before decrypting so Size is the payload as sent.
+	event := Event{
+		StreamID:     ssp.StreamID,
+		PacketNumber: ssp.StreamPacketNumber,
+		Size:         len(ssp.Payload),
+		Encoding:     ssp.StreamEncoding,
+	}
+
+	// Authenticate before the packet can take the place of the genuine one.
+	if err := a.decrypt(ssp); err != nil {
+		return err
+	}
+
+	_, buffered := a.packets[ssp.StreamPacketNumber]
+	if buffered || a.current > ssp.StreamPacketNumber {
+		if err := a.checkDuplicate(ssp); err != nil {
+			event.Kind = EventConflictingDuplicate
+			event.Err = err
+			a.observe(event)
+			return err
+		}
+	}
+
 	// We're past the current packet, so it can be dropped.
 	if a.current > ssp.StreamPacketNumber {
+		a.duplicates.Late++
+		event.Kind = EventLatePacketDropped
+		a.observe(event)
 		return nil
 	}
 
 	// We have the current packet already, so it can be dropped.
-	if _, found := a.packets[ssp.StreamPacketNumber]; found {
+	if buffered {
+		a.duplicates.Buffered++
+		event.Kind = EventDuplicateDropped
+		a.observe(event)
 		return nil
 	}
 
@@ -280,15 +626,50 @@ func (a *Assembler) ProcessWRP(_ context.Context, msg wrp.Message) error {
 	if a.MaxPacketGap > 0 {
 		gap := ssp.StreamPacketNumber - a.current
 		if gap > int64(a.MaxPacketGap) {
-			return &packetGapExceeded{
+			err := &packetGapExceeded{
 				current:  a.current,
 				received: ssp.StreamPacketNumber,
 				maxGap:   a.MaxPacketGap,
 			}
+			event.Kind = EventGapExceeded
+			event.Err = err
+			a.observe(event)
+			return err
+		}
+	}
+
+	if err := a.checkLimits(ssp); err != nil {
+		return err
+	}
+
+	if err := a.checkProtocol(ssp); err != nil {
+		a.fail(err)
+		return err
+	}
+	a.track(ssp)
+	a.checkEnvelope(ssp)
+
+	if a.metadata == nil {
+		metadata := StreamMetadata{
+			Name:        ssp.StreamName,
+			ContentType: ssp.StreamContentType,
+			ModTime:     ssp.StreamModTime,
+		}
+		if !metadata.isZero() {
+			a.metadata = &metadata
 		}
 	}
 
-	a.packets[ssp.StreamPacketNumber] = &ssp
+	if a.streamID == "" {
+		a.streamID = ssp.StreamID
+		a.source = ssp.Source
+		a.destination = ssp.Destination
+	}
+
+	a.packets[ssp.StreamPacketNumber] = ssp
+	a.bytes += len(ssp.Payload)
+	a.highest = max(a.highest, ssp.StreamPacketNumber)
+	a.progress.packet(time.Now(), event.Size, ssp.StreamEstimatedLength)
 
 	// Signal waiting readers that data is available
 	a.signal()
@@ -296,6 +677,47 @@ func (a *Assembler) ProcessWRP(_ context.Context, msg wrp.Message) error {
 	return nil
 }
 
+// checkLimits checks that buffering the packet stays within the limits.  The
+// next packet to be read is always accepted so the stream can make progress.
+// Must be called with the lock held.
+func (a *Assembler) checkLimits(ssp *simpleStreamingMessage) error {
+	if ssp.StreamPacketNumber == a.current {
+		return nil
+	}
+
+	if a.MaxBufferedPackets > 0 && len(a.packets) >= a.MaxBufferedPackets {
+		return &bufferFull{
+			number: ssp.StreamPacketNumber,
+			limit:  "packets",
+			max:    a.MaxBufferedPackets,
+		}
+	}
+
+	if a.MaxBufferedBytes > 0 && a.bytes+len(ssp.Payload) > a.MaxBufferedBytes {
+		return &bufferFull{
+			number: ssp.StreamPacketNumber,
+			limit:  "bytes",
+			max:    a.MaxBufferedBytes,
+		}
+	}
+
+	return nil
+}
+
+// remove drops the buffered packet.  Must be called with the lock held.
+func (a *Assembler) remove(num int64) {
+	if packet, found := a.packets[num]; found {
+		a.bytes -= len(packet.Payload)
+		delete(a.packets, num)
+	}
+}
+
+// removeAll drops every buffered packet.  Must be called with the lock held.
+func (a *Assembler) removeAll() {
+	clear(a.packets)
+	a.bytes = 0
+}
+
 // signal notifies a waiting reader (must be called with lock held)
 func (a *Assembler) signal() {
 	if a.event != nil {
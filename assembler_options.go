@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// AssemblerOption is a functional option for the Assembler.
+type AssemblerOption interface {
+	apply(*Assembler) error
+}
+
+type assemblerOptionFunc func(*Assembler) error
+
+func (f assemblerOptionFunc) apply(assembler *Assembler) error {
+	return f(assembler)
+}
+
+// AssemblerValidators adds validators used when each packet is parsed.  This is
+// optional.
+func AssemblerValidators(validators ...wrp.Processor) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		a.Validators = append(a.Validators, validators...)
+		return nil
+	})
+}
+
+// AssemblerMaxPacketGap sets the largest gap allowed between the next packet
+// to be read and a packet received.  This is optional.  The gap must not be
+// negative; 0 means unlimited.
+func AssemblerMaxPacketGap(gap int) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if gap < 0 {
+			return fmt.Errorf("%w: max packet gap must not be negative", ErrInvalidInput)
+		}
+		a.MaxPacketGap = gap
+		return nil
+	})
+}
+
+// AssemblerMaxBufferedPackets sets the most packets buffered waiting to be
+// read.  This is optional.  The limit must not be negative; 0 means unlimited.
+func AssemblerMaxBufferedPackets(n int) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if n < 0 {
+			return fmt.Errorf("%w: max buffered packets must not be negative", ErrInvalidInput)
+		}
+		a.MaxBufferedPackets = n
+		return nil
+	})
+}
+
+// AssemblerMaxBufferedBytes sets the most payload bytes buffered waiting to be
+// read.  This is optional.  The limit must not be negative; 0 means unlimited.
+func AssemblerMaxBufferedBytes(n int) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if n < 0 {
+			return fmt.Errorf("%w: max buffered bytes must not be negative", ErrInvalidInput)
+		}
+		a.MaxBufferedBytes = n
+		return nil
+	})
+}
+
+// AssemblerIdleTimeout sets how long Read waits without any packet being
+// received before the stream ends with an error matching ErrStreamTimeout.
+// This is optional.  The timeout must not be negative; 0 means unlimited.
+func AssemblerIdleTimeout(d time.Duration) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if d < 0 {
+			return fmt.Errorf("%w: idle timeout must not be negative", ErrInvalidInput)
+		}
+		a.IdleTimeout = d
+		return nil
+	})
+}
+
+// AssemblerStreamID sets the stream to assemble.  Packets of other streams are
+// rejected with wrp.ErrNotHandled.  This is optional.  The ID must only
+// contain [A-Za-z0-9_-].
+func AssemblerStreamID(id string) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if !validID.MatchString(id) {
+			return fmt.Errorf("%w: stream id is empty or contains invalid characters", ErrInvalidInput)
+		}
+		a.StreamID = id
+		return nil
+	})
+}
+
+// AssemblerKeys sets the keys used to decrypt the packets.  When set, every
+// packet must be encrypted.  This is optional.
+func AssemblerKeys(keys KeyProvider) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if keys == nil {
+			return fmt.Errorf("%w: keys must not be nil", ErrInvalidInput)
+		}
+		a.Keys = keys
+		return nil
+	})
+}
+
+// AssemblerVerifier sets the verifier of the packet signatures.  When set,
+// every packet must be signed.  This is optional.
+func AssemblerVerifier(verifier Verifier) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if verifier == nil {
+			return fmt.Errorf("%w: verifier must not be nil", ErrInvalidInput)
+		}
+		a.Verifier = verifier
+		return nil
+	})
+}
+
+// AssemblerObserver sets the Observer that receives the events of the stream.
+// This is optional.
+func AssemblerObserver(o Observer) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if o == nil {
+			return fmt.Errorf("%w: observer must not be nil", ErrInvalidInput)
+		}
+		a.Observer = o
+		return nil
+	})
+}
+
+// AssemblerProgress sets a function called with the progress of the stream at
+// most once per interval, and once when the stream ends.  This is optional.
+// The interval must not be negative; 0 calls it for every packet.
+func AssemblerProgress(fn func(Progress), interval time.Duration) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if fn == nil {
+			return fmt.Errorf("%w: progress function must not be nil", ErrInvalidInput)
+		}
+		if interval < 0 {
+			return fmt.Errorf("%w: progress interval must not be negative", ErrInvalidInput)
+		}
+		a.OnProgress = fn
+		a.ProgressInterval = interval
+		return nil
+	})
+}
+
+// AssemblerStrict enables ending the stream with a ProtocolViolationError when
+// a packet breaks the rules of the protocol.  This is optional.
+func AssemblerStrict(enabled bool) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		a.Strict = enabled
+		return nil
+	})
+}
+
+// AssemblerStrictHeaders enables checking the headers of each packet against
+// the grammar in protocol.md.  This is optional.
+func AssemblerStrictHeaders(enabled bool) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		a.StrictHeaders = enabled
+		return nil
+	})
+}
+
+// AssemblerStrictDuplicates enables rejecting packets received again with a
+// different payload, keeping the digests of the last history packets read.
+// This is optional.  The history must not be negative; 0 uses the default of
+// 64.
+func AssemblerStrictDuplicates(enabled bool, history int) AssemblerOption {
+	return assemblerOptionFunc(func(a *Assembler) error {
+		if history < 0 {
+			return fmt.Errorf("%w: duplicate history must not be negative", ErrInvalidInput)
+		}
+		a.StrictDuplicates = enabled
+		a.DuplicateHistory = history
+		return nil
+	})
+}
+
+// NewAssembler creates a new Assembler with the given options.  The zero value
+// Assembler is also ready to use, configured with its exported fields.
+func NewAssembler(opts ...AssemblerOption) (*Assembler, error) {
+	var a Assembler
+
+	for _, opt := range opts {
+		if err := opt.apply(&a); err != nil {
+			return nil, err
+		}
+	}
+
+	a.init()
+
+	return &a, nil
+}
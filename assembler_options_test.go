@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestNewAssembler(t *testing.T) {
+	var r recorder
+	progress := func(Progress) {}
+
+	tests := []struct {
+		name    string
+		opts    []AssemblerOption
+		check   func(*testing.T, *Assembler)
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, a *Assembler) {
+				assert.Zero(t, a.MaxPacketGap)
+				assert.Zero(t, a.MaxBufferedPackets)
+				assert.Zero(t, a.IdleTimeout)
+				assert.NotNil(t, a.packets)
+			},
+		}, {
+			name: "all options",
+			opts: []AssemblerOption{
+				AssemblerValidators(wrp.NoStandardValidation()),
+				AssemblerMaxPacketGap(3),
+				AssemblerMaxBufferedPackets(4),
+				AssemblerMaxBufferedBytes(100),
+				AssemblerIdleTimeout(time.Second),
+				AssemblerStreamID("abc"),
+				AssemblerKeys(StaticKeys{"k": make([]byte, 32)}),
+				AssemblerVerifier(HMACVerifier([]byte("secret"))),
+				AssemblerObserver(&r),
+				AssemblerProgress(progress, time.Minute),
+				AssemblerStrict(true),
+				AssemblerStrictHeaders(true),
+				AssemblerStrictDuplicates(true, 10),
+			},
+			check: func(t *testing.T, a *Assembler) {
+				assert.Len(t, a.Validators, 1)
+				assert.Equal(t, 3, a.MaxPacketGap)
+				assert.Equal(t, 4, a.MaxBufferedPackets)
+				assert.Equal(t, 100, a.MaxBufferedBytes)
+				assert.Equal(t, time.Second, a.IdleTimeout)
+				assert.Equal(t, "abc", a.StreamID)
+				assert.NotNil(t, a.Keys)
+				assert.NotNil(t, a.Verifier)
+				assert.Equal(t, &r, a.Observer)
+				assert.NotNil(t, a.OnProgress)
+				assert.Equal(t, time.Minute, a.ProgressInterval)
+				assert.True(t, a.Strict)
+				assert.True(t, a.StrictHeaders)
+				assert.True(t, a.StrictDuplicates)
+				assert.Equal(t, 10, a.DuplicateHistory)
+			},
+		},
+		{name: "negative gap", opts: []AssemblerOption{AssemblerMaxPacketGap(-1)}, wantErr: ErrInvalidInput},
+		{name: "negative packets", opts: []AssemblerOption{AssemblerMaxBufferedPackets(-1)}, wantErr: ErrInvalidInput},
+		{name: "negative bytes", opts: []AssemblerOption{AssemblerMaxBufferedBytes(-1)}, wantErr: ErrInvalidInput},
+		{name: "negative timeout", opts: []AssemblerOption{AssemblerIdleTimeout(-time.Second)}, wantErr: ErrInvalidInput},
+		{name: "empty stream id", opts: []AssemblerOption{AssemblerStreamID("")}, wantErr: ErrInvalidInput},
+		{name: "invalid stream id", opts: []AssemblerOption{AssemblerStreamID("a b")}, wantErr: ErrInvalidInput},
+		{name: "nil keys", opts: []AssemblerOption{AssemblerKeys(nil)}, wantErr: ErrInvalidInput},
+		{name: "nil verifier", opts: []AssemblerOption{AssemblerVerifier(nil)}, wantErr: ErrInvalidInput},
+		{name: "nil observer", opts: []AssemblerOption{AssemblerObserver(nil)}, wantErr: ErrInvalidInput},
+		{name: "nil progress", opts: []AssemblerOption{AssemblerProgress(nil, 0)}, wantErr: ErrInvalidInput},
+		{name: "negative interval", opts: []AssemblerOption{AssemblerProgress(progress, -1)}, wantErr: ErrInvalidInput},
+		{name: "negative history", opts: []AssemblerOption{AssemblerStrictDuplicates(true, -1)}, wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAssembler(tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, a)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, a)
+			tt.check(t, a)
+		})
+	}
+}
+
+func TestAssembler_Limits(t *testing.T) {
+	packet := func(id string, n int, payload string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers: []string{
+				"stream-id: " + id,
+				"stream-packet-number: " + strconv.Itoa(n),
+			},
+			Payload: []byte(payload),
+		}
+	}
+
+	t.Run("stream id", func(t *testing.T) {
+		a, err := NewAssembler(AssemblerStreamID("abc"))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, a.ProcessWRP(context.Background(), packet("xyz", 0, "Hello")), wrp.ErrNotHandled)
+		assert.NoError(t, a.ProcessWRP(context.Background(), packet("abc", 0, "Hello")))
+	})
+
+	t.Run("buffered packets", func(t *testing.T) {
+		a, err := NewAssembler(AssemblerMaxBufferedPackets(2))
+		require.NoError(t, err)
+
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 2, "2")))
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 3, "3")))
+		err = a.ProcessWRP(context.Background(), packet("1", 4, "4"))
+		assert.ErrorIs(t, err, ErrBufferFull)
+		assert.ErrorContains(t, err, "packet 4 would exceed the limit of 2 buffered packets")
+
+		// The next packet to read is always accepted.
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 0, "0")))
+
+		buf := make([]byte, 1)
+		_, err = io.ReadFull(a, buf)
+		require.NoError(t, err)
+		assert.NoError(t, a.ProcessWRP(context.Background(), packet("1", 1, "1")))
+	})
+
+	t.Run("buffered bytes", func(t *testing.T) {
+		a, err := NewAssembler(AssemblerMaxBufferedBytes(8))
+		require.NoError(t, err)
+
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 1, "Hello")))
+		assert.ErrorIs(t, a.ProcessWRP(context.Background(), packet("1", 2, "World")), ErrBufferFull)
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 0, "Hello")))
+
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(a, buf)
+		require.NoError(t, err)
+		assert.Zero(t, a.bytes)
+		assert.NoError(t, a.ProcessWRP(context.Background(), packet("1", 2, "World")))
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		var r recorder
+		a, err := NewAssembler(
+			AssemblerIdleTimeout(10*time.Millisecond),
+			AssemblerObserver(&r),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, a.ProcessWRP(context.Background(), packet("1", 0, "Hello")))
+
+		got, err := io.ReadAll(a)
+		assert.Equal(t, "Hello", string(got))
+		assert.ErrorIs(t, err, ErrStreamTimeout)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.ErrorContains(t, err, "no packet received for 10ms")
+
+		assert.Equal(t, []EventKind{EventStreamAborted}, r.kinds())
+		assert.ErrorIs(t, a.ProcessWRP(context.Background(), packet("1", 1, "World")), ErrClosed)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xmidt-org/wrp-go/v5"
 )
 
@@ -335,6 +336,149 @@ func TestAssembler_ProcessWRP_PacketGap(t *testing.T) {
 	}
 }
 
+func TestAssembler_Summary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		final    []string
+		want     string
+		err      error
+		mismatch *SummaryMismatchError
+	}{
+		{
+			name:  "summary matches",
+			final: []string{"stream-final-packet: eof", "stream-total-packets: 3", "stream-total-length: 15"},
+			want:  "Hello, World!!!",
+			err:   io.EOF,
+		}, {
+			name:  "no summary",
+			final: []string{"stream-final-packet: eof"},
+			want:  "Hello, World!!!",
+			err:   io.EOF,
+		}, {
+			name:  "final packet contradicts the total",
+			final: []string{"stream-final-packet: eof", "stream-total-packets: 5", "stream-total-length: 25"},
+			want:  "Hello, World!!!",
+			mismatch: &SummaryMismatchError{
+				Header:   "stream-total-packets",
+				Expected: 5,
+				Actual:   3,
+			},
+		}, {
+			name:  "length differs",
+			final: []string{"stream-final-packet: eof", "stream-total-packets: 3", "stream-total-length: 16"},
+			want:  "Hello, World!!!",
+			mismatch: &SummaryMismatchError{
+				Header:   "stream-total-length",
+				Expected: 16,
+				Actual:   15,
+			},
+		}, {
+			name:  "mismatch takes precedence over the reason",
+			final: []string{"stream-final-packet: aborted", "stream-total-packets: 4", "stream-total-length: 20"},
+			want:  "Hello, World!!!",
+			mismatch: &SummaryMismatchError{
+				Header:   "stream-total-packets",
+				Expected: 4,
+				Actual:   3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt // Capture range variable for parallel subtest
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var assembler Assembler
+			for i, payload := range []string{"Hello", ", Wor", "ld!!!"} {
+				headers := []string{
+					"stream-id: 1",
+					fmt.Sprintf("stream-packet-number: %d", i),
+				}
+				if i == 2 {
+					headers = append(headers, tt.final...)
+				}
+
+				err := assembler.ProcessWRP(context.Background(), wrp.Message{
+					Type:        wrp.SimpleEventMessageType,
+					Source:      "mac:112233445566",
+					Destination: "event:status/mac:112233445566",
+					Headers:     headers,
+					Payload:     []byte(payload),
+				})
+				assert.NoError(t, err)
+			}
+
+			buf := make([]byte, 100)
+			n, err := io.ReadFull(&assembler, buf)
+			assert.Equal(t, tt.want, string(buf[:n]))
+			assert.Error(t, err)
+
+			if tt.mismatch == nil {
+				assert.Equal(t, tt.err, assembler.final)
+				return
+			}
+
+			var mismatch *SummaryMismatchError
+			if assert.ErrorAs(t, err, &mismatch) {
+				assert.Equal(t, tt.mismatch, mismatch)
+			}
+			assert.ErrorIs(t, err, ErrSummaryMismatch)
+			assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		})
+	}
+}
+
+func TestAssembler_SummaryDetectsMismatch(t *testing.T) {
+	packet := func(n int, payload string, headers ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:status/mac:112233445566",
+			Headers:     append([]string{"stream-id: 1", fmt.Sprintf("stream-packet-number: %d", n)}, headers...),
+			Payload:     []byte(payload),
+		}
+	}
+	final := []string{"stream-final-packet: eof", "stream-total-packets: 2", "stream-total-length: 10"}
+
+	t.Run("packet after the final packet", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet(0, "Hello")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet(1, "World", final...)))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet(2, "!")))
+
+		got, err := io.ReadAll(&assembler)
+		assert.Equal(t, "HelloWorld", string(got))
+
+		var mismatch *SummaryMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, &SummaryMismatchError{
+			Header:   "stream-total-packets",
+			Expected: 2,
+			Actual:   3,
+		}, mismatch)
+	})
+
+	t.Run("truncated payload", func(t *testing.T) {
+		var assembler Assembler
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet(0, "Hel")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet(1, "World", final...)))
+
+		got, err := io.ReadAll(&assembler)
+		assert.Equal(t, "HelWorld", string(got))
+
+		var mismatch *SummaryMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, &SummaryMismatchError{
+			Header:   "stream-total-length",
+			Expected: 10,
+			Actual:   8,
+		}, mismatch)
+	})
+}
+
 func TestAssembler_ProcessWRP(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+const (
+	// These are the string literals found in the cancel control message.
+	stream_cancel        = "stream-cancel"
+	stream_cancel_reason = "stream-cancel-reason"
+)
+
+// NewCancel creates the cancel control message a receiver sends to ask the
+// producer of the stream to stop sending.  The message is addressed to the
+// Source of the stream's packets (to) from the Destination of the stream's
+// packets (from).
+func NewCancel(streamID, to, from string, reason Reason) wrp.Message {
+	return wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      from,
+		Destination: to,
+		Headers: []string{
+			stream_cancel + ": " + streamID,
+			stream_cancel_reason + ": " + reason.String(),
+		},
+	}
+}
+
+// ParseCancel returns the stream ID and reason of a cancel control message.
+// If the message is not a cancel control message, wrp.ErrNotHandled is
+// returned.
+func ParseCancel(msg wrp.Message) (string, Reason, error) {
+	if msg.Type != wrp.SimpleEventMessageType {
+		return "", Reason{}, wrp.ErrNotHandled
+	}
+
+	var id string
+	var reason Reason
+	for _, header := range msg.Headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case stream_cancel:
+			id = strings.TrimSpace(value)
+		case stream_cancel_reason:
+			reason = ParseReason(value)
+		}
+	}
+
+	if id == "" {
+		return "", Reason{}, wrp.ErrNotHandled
+	}
+
+	return id, reason, nil
+}
+
+// Cancel closes the Assembler early and returns the cancel control message to
+// send to the producer of the stream.  Any buffered packets are dropped, and
+// Read returns an error wrapping ErrCanceledByReceiver.
+//
+// If no packets have been received, the producer is unknown and
+// ErrNotAvailable is returned along with a nil message; the Assembler is
+// still closed.
+func (a *Assembler) Cancel(reason Reason) (*wrp.Message, error) {
+	a.init()
+
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	a.fail(&canceledByReceiver{reason: reason})
+
+	if a.streamID == "" {
+		return nil, ErrNotAvailable
+	}
+
+	msg := NewCancel(a.streamID, a.source, a.destination, reason)
+	return &msg, nil
+}
+
+// CancelWatcher watches for a cancel control message for a stream and cancels
+// a context when one arrives.  The CancelWatcher implements the wrp.Processor
+// interface so it can be placed where the producer receives messages.
+type CancelWatcher struct {
+	id     string
+	cancel context.CancelCauseFunc
+	once   sync.Once
+}
+
+// WatchCancel returns a context derived from ctx and a CancelWatcher for the
+// stream with the ID.  When the CancelWatcher processes a cancel control
+// message for the stream, the context is canceled with a cause wrapping
+// ErrCanceledByReceiver.  Passing the context to Packetizer.Next stops the
+// stream with that error.
+//
+// Stop should be called once the stream is complete to release the context.
+func WatchCancel(ctx context.Context, streamID string) (context.Context, *CancelWatcher) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return ctx, &CancelWatcher{
+		id:     streamID,
+		cancel: cancel,
+	}
+}
+
+var _ wrp.Processor = (*CancelWatcher)(nil)
+
+// ProcessWRP implements the wrp.Processor interface.  Messages that are not
+// cancel control messages for the stream return wrp.ErrNotHandled.
+func (w *CancelWatcher) ProcessWRP(_ context.Context, msg wrp.Message) error {
+	id, reason, err := ParseCancel(msg)
+	if err != nil {
+		return err
+	}
+
+	if id != w.id {
+		return wrp.ErrNotHandled
+	}
+
+	w.once.Do(func() {
+		w.cancel(&canceledByReceiver{reason: reason})
+	})
+
+	return nil
+}
+
+// Stop releases the context returned by WatchCancel.
+func (w *CancelWatcher) Stop() {
+	w.once.Do(func() {
+		w.cancel(context.Canceled)
+	})
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// endlessReader produces an unending stream of the same byte.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestNewCancel(t *testing.T) {
+	msg := NewCancel("123", "mac:112233445566", "event:uploads", Reason{Code: "quota-exceeded", Message: "disk full"})
+
+	assert.Equal(t, wrp.SimpleEventMessageType, msg.Type)
+	assert.Equal(t, "event:uploads", msg.Source)
+	assert.Equal(t, "mac:112233445566", msg.Destination)
+	assert.Equal(t, []string{
+		"stream-cancel: 123",
+		"stream-cancel-reason: quota-exceeded: disk full",
+	}, msg.Headers)
+
+	// A cancel control message is not a stream packet.
+	assert.False(t, Is(&msg))
+
+	id, reason, err := ParseCancel(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", id)
+	assert.Equal(t, Reason{Code: "quota-exceeded", Message: "disk full"}, reason)
+}
+
+func TestParseCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  wrp.Message
+		id   string
+		err  error
+	}{
+		{
+			name: "case and whitespace are ignored",
+			msg: wrp.Message{
+				Type:    wrp.SimpleEventMessageType,
+				Headers: []string{" Stream-Cancel :  123 ", "no colon"},
+			},
+			id: "123",
+		}, {
+			name: "wrong message type",
+			msg: wrp.Message{
+				Type:    wrp.SimpleRequestResponseMessageType,
+				Headers: []string{"stream-cancel: 123"},
+			},
+			err: wrp.ErrNotHandled,
+		}, {
+			name: "stream packet",
+			msg: wrp.Message{
+				Type:    wrp.SimpleEventMessageType,
+				Headers: []string{"stream-id: 123", "stream-packet-number: 0"},
+			},
+			err: wrp.ErrNotHandled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, _, err := ParseCancel(tt.msg)
+			assert.Equal(t, tt.id, id)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAssembler_Cancel(t *testing.T) {
+	t.Run("before any packets", func(t *testing.T) {
+		var a Assembler
+		msg, err := a.Cancel(Reason{Code: ReasonCanceled})
+		assert.Nil(t, msg)
+		assert.ErrorIs(t, err, ErrNotAvailable)
+
+		n, err := a.Read(make([]byte, 10))
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, ErrCanceledByReceiver)
+		assert.ErrorIs(t, a.ProcessWRP(context.Background(), wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:uploads",
+			Headers:     []string{"stream-id: 123", "stream-packet-number: 0"},
+		}), ErrClosed)
+	})
+
+	t.Run("after packets", func(t *testing.T) {
+		var a Assembler
+		require.NoError(t, a.ProcessWRP(context.Background(), wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:uploads",
+			Headers:     []string{"stream-id: 123", "stream-packet-number: 1"},
+			Payload:     []byte("buffered"),
+		}))
+
+		msg, err := a.Cancel(Reason{Code: "quota-exceeded"})
+		assert.NoError(t, err)
+		require.NotNil(t, msg)
+		assert.Equal(t, "event:uploads", msg.Source)
+		assert.Equal(t, "mac:112233445566", msg.Destination)
+		assert.Contains(t, msg.Headers, "stream-cancel: 123")
+		assert.Contains(t, msg.Headers, "stream-cancel-reason: quota-exceeded")
+
+		got, err := io.ReadAll(&a)
+		assert.Empty(t, got)
+		assert.ErrorIs(t, err, ErrCanceledByReceiver)
+		assert.Empty(t, a.packets)
+	})
+}
+
+func TestCancelWatcher(t *testing.T) {
+	ctx, watcher := WatchCancel(context.Background(), "123")
+	defer watcher.Stop()
+
+	// Messages for other streams or that are not cancel messages are ignored.
+	other := NewCancel("456", "mac:112233445566", "event:uploads", Reason{Code: ReasonCanceled})
+	assert.ErrorIs(t, watcher.ProcessWRP(context.Background(), other), wrp.ErrNotHandled)
+	assert.ErrorIs(t, watcher.ProcessWRP(context.Background(), wrp.Message{Type: wrp.SimpleEventMessageType}), wrp.ErrNotHandled)
+	assert.NoError(t, ctx.Err())
+
+	msg := NewCancel("123", "mac:112233445566", "event:uploads", Reason{Code: "quota-exceeded"})
+	assert.NoError(t, watcher.ProcessWRP(context.Background(), msg))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.ErrorIs(t, context.Cause(ctx), ErrCanceledByReceiver)
+}
+
+// TestCancel_Loopback sends a stream from a Packetizer to an Assembler and the
+// cancel control message back to the producer over in-memory channels.
+func TestCancel_Loopback(t *testing.T) {
+	packetizer, err := New(
+		ID("loopback"),
+		Reader(endlessReader{}),
+		MaxPacketSize(10),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	toReceiver := make(chan wrp.Message)
+	toSender := make(chan wrp.Message, 1)
+
+	ctx, watcher := WatchCancel(context.Background(), "loopback")
+	defer watcher.Stop()
+
+	// Route messages arriving at the producer to the watcher.
+	go func() {
+		for msg := range toSender {
+			_ = watcher.ProcessWRP(context.Background(), msg)
+		}
+	}()
+
+	senderErr := make(chan error, 1)
+	go func() {
+		defer close(toReceiver)
+		in := wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:uploads",
+		}
+		for msg, err := range packetizer.Packets(ctx, in) {
+			if msg != nil {
+				toReceiver <- *msg
+			}
+			if err != nil {
+				senderErr <- err
+				return
+			}
+		}
+	}()
+
+	var assembler Assembler
+	last := make(chan wrp.Message, 1)
+	go func() {
+		var msg wrp.Message
+		for msg = range toReceiver {
+			_ = assembler.ProcessWRP(context.Background(), msg)
+		}
+		last <- msg
+	}()
+
+	buf := make([]byte, 25)
+	_, err = io.ReadFull(&assembler, buf)
+	require.NoError(t, err)
+
+	// The consumer has seen enough.
+	cancel, err := assembler.Cancel(Reason{Code: "quota-exceeded"})
+	require.NoError(t, err)
+	toSender <- *cancel
+	close(toSender)
+
+	select {
+	case err = <-senderErr:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the producer did not stop")
+	}
+
+	assert.ErrorIs(t, err, ErrCanceledByReceiver)
+	assert.False(t, errors.Is(err, context.Canceled))
+
+	var reason *canceledByReceiver
+	require.ErrorAs(t, err, &reason)
+	assert.Equal(t, Reason{Code: "quota-exceeded"}, reason.reason)
+
+	// The producer ended the stream with a final canceled packet.
+	final := <-last
+	assert.Contains(t, final.Headers, "stream-final-packet: canceled")
+}
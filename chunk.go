@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"slices"
+)
+
+// Chunk is the decoded data of one packet of the stream, returned by
+// NextPacket.
+type Chunk struct {
+	// PacketNumber is the stream-packet-number of the packet.
+	PacketNumber int64
+
+	// Offset is the position of the first byte of Data in the stream.
+	Offset uint64
+
+	// Data is the decoded payload of the packet.  If part of the packet was
+	// already read with Read, only the rest of it is included.
+	Data []byte
+
+	// Encoding is the stream-encoding the packet was sent with.
+	Encoding Encoding
+
+	// Headers are the headers of the packet that are not SSP headers.
+	Headers []string
+
+	// Final is true for the final packet of the stream.
+	Final bool
+
+	// Buffered is the number of later packets already received and waiting
+	// to be read, which arrived ahead of a gap in the stream.
+	Buffered int
+}
+
+// NextPacket returns the next packet of the stream in order, so consumers that
+// need the packet boundaries, such as record-oriented logs, can keep them.  It
+// blocks until the packet is received, the stream ends or the context is
+// canceled.  The packet is consumed, so NextPacket and Read may be used on
+// the same stream, but not concurrently.
+//
+// Once the final packet has been returned, the next call returns io.EOF, or
+// the error the stream ended with.
+func (a *Assembler) NextPacket(ctx context.Context) (*Chunk, error) {
+	a.init()
+
+	for {
+		a.m.Lock()
+		chunk, err := a.nextChunk()
+		var n int
+		if chunk != nil {
+			n = len(chunk.Data)
+		}
+		report := a.consumed(n, err)
+		a.m.Unlock()
+
+		report()
+
+		if chunk != nil || err != nil {
+			return chunk, err
+		}
+
+		if err := a.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextChunk consumes the rest of the current packet.  It returns nil and no
+// error if the packet has not been received yet.  Must be called with the lock
+// held.
+func (a *Assembler) nextChunk() (*Chunk, error) {
+	packet, buf, err := a.getPacket(a.current)
+	if err != nil || packet == nil {
+		// Let read report the end of the stream or the decoding error.
+		_, err := a.read(nil)
+		return nil, err
+	}
+
+	chunk := Chunk{
+		PacketNumber: a.current,
+		Offset:       a.length + uint64(a.offset), // nolint:gosec
+		Data:         make([]byte, len(buf)-a.offset),
+		Encoding:     packet.StreamEncoding,
+		Headers:      slices.Clone(packet.Headers),
+		Final:        packet.StreamFinalPacket != "",
+	}
+
+	if chunk.Encoding == "" {
+		chunk.Encoding = EncodingIdentity
+	}
+
+	// The error for the end of the stream is returned by the next call.
+	_, _ = a.read(chunk.Data)
+	chunk.Buffered = len(a.packets)
+
+	return &chunk, nil
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestAssembler_NextPacket(t *testing.T) {
+	packetizer, err := New(
+		ID("123"),
+		Reader(strings.NewReader("HelloWorld!")),
+		MaxPacketSize(5),
+		WithEncoding(EncodingGzip),
+		WithTrailers(func() (map[string]string, error) {
+			return map[string]string{"count": "3"}, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	var r recorder
+	assembler := Assembler{Observer: &r}
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+		Headers:     []string{"record: log"},
+	}
+
+	var packets []*wrp.Message
+	for packet, err := range packetizer.Packets(context.Background(), in) {
+		require.NoError(t, err)
+		packets = append(packets, packet)
+	}
+	require.Len(t, packets, 3)
+
+	// Deliver the packets out of order.
+	for _, i := range []int{2, 0, 1} {
+		require.NoError(t, assembler.ProcessWRP(context.Background(), *packets[i]))
+	}
+
+	var got []Chunk
+	for {
+		chunk, err := assembler.NextPacket(context.Background())
+		if errors.Is(err, io.EOF) {
+			assert.Nil(t, chunk)
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, *chunk)
+	}
+
+	want := []Chunk{
+		{PacketNumber: 0, Offset: 0, Data: []byte("Hello"), Buffered: 2},
+		{PacketNumber: 1, Offset: 5, Data: []byte("World"), Buffered: 1},
+		{PacketNumber: 2, Offset: 10, Data: []byte("!"), Final: true},
+	}
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.Equal(t, want[i].PacketNumber, got[i].PacketNumber)
+		assert.Equal(t, want[i].Offset, got[i].Offset)
+		assert.Equal(t, want[i].Data, got[i].Data)
+		assert.Equal(t, want[i].Final, got[i].Final)
+		assert.Equal(t, want[i].Buffered, got[i].Buffered)
+		assert.Equal(t, []string{"record: log"}, got[i].Headers)
+	}
+	assert.Equal(t, EncodingGzip, got[0].Encoding)
+
+	trailers, err := assembler.Trailers()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "3"}, trailers)
+	assert.Equal(t, []EventKind{EventStreamCompleted}, r.kinds())
+	assert.True(t, assembler.Progress().Done)
+
+	// The end of the stream is sticky.
+	_, err = assembler.NextPacket(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestAssembler_NextPacketWithRead(t *testing.T) {
+	packet := func(n string, payload string, headers ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers:     append([]string{"stream-id: 1", "stream-packet-number: " + n}, headers...),
+			Payload:     []byte(payload),
+		}
+	}
+
+	var assembler Assembler
+	require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+
+	buf := make([]byte, 3)
+	_, err := io.ReadFull(&assembler, buf)
+	require.NoError(t, err)
+
+	chunk, err := assembler.NextPacket(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Chunk{
+		PacketNumber: 0,
+		Offset:       3,
+		Data:         []byte("lo"),
+		Encoding:     EncodingIdentity,
+	}, chunk)
+
+	// Waiting for the next packet stops when the context is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = assembler.NextPacket(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = assembler.ProcessWRP(context.Background(), packet("1", "World", "stream-final-packet: timeout"))
+	}()
+
+	chunk, err = assembler.NextPacket(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "World", string(chunk.Data))
+	assert.Equal(t, uint64(5), chunk.Offset)
+	assert.True(t, chunk.Final)
+
+	// The stream ended early.
+	_, err = assembler.NextPacket(context.Background())
+	assert.ErrorIs(t, err, ErrStreamTimeout)
+	n, err := assembler.Read(make([]byte, 1))
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, ErrStreamTimeout)
+}
+
+func TestAssembler_NextPacketDecodeFailure(t *testing.T) {
+	var assembler Assembler
+	require.NoError(t, assembler.ProcessWRP(context.Background(), wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+		Headers:     []string{"stream-id: 1", "stream-packet-number: 0", "stream-encoding: gzip"},
+		Payload:     []byte("not gzip"),
+	}))
+
+	chunk, err := assembler.NextPacket(context.Background())
+	assert.Nil(t, chunk)
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// defaultDuplicateHistory is the number of packets read that the digests are
+// kept for when Assembler.DuplicateHistory is not set.
+const defaultDuplicateHistory = 64
+
+// DuplicateStats counts the duplicate packets the Assembler dropped because
+// the packet was already received.
+type DuplicateStats struct {
+	// Buffered is the number of duplicates of packets that were waiting to be
+	// read.
+	Buffered int64
+
+	// Late is the number of duplicates of packets that were already read.
+	Late int64
+}
+
+// Duplicates returns the number of duplicate packets that were dropped.  When
+// StrictDuplicates is set, conflicting duplicates are not counted.
+func (a *Assembler) Duplicates() DuplicateStats {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a.duplicates
+}
+
+// checkDuplicate compares the packet with the packet with the same number that
+// was already received, if StrictDuplicates is set.  Packets that were read too
+// long ago to have a digest are not checked.  Must be called with the lock
+// held.
+func (a *Assembler) checkDuplicate(ssp *simpleStreamingMessage) error {
+	if !a.StrictDuplicates {
+		return nil
+	}
+
+	number := ssp.StreamPacketNumber
+	if buffered, found := a.packets[number]; found {
+		if !bytes.Equal(buffered.Payload, ssp.Payload) {
+			return &conflictingDuplicate{number: number}
+		}
+		return nil
+	}
+
+	if digest, found := a.digests[number]; found {
+		if digest != sha256.Sum256(ssp.Payload) {
+			return &conflictingDuplicate{number: number, consumed: true}
+		}
+	}
+
+	return nil
+}
+
+// keepDigest records the digest of a packet that has been read, and forgets
+// the digests of packets older than the DuplicateHistory.  Must be called with
+// the lock held.
+func (a *Assembler) keepDigest(packet *simpleStreamingMessage) {
+	if !a.StrictDuplicates {
+		return
+	}
+
+	history := int64(a.DuplicateHistory)
+	if history <= 0 {
+		history = defaultDuplicateHistory
+	}
+
+	if a.digests == nil {
+		a.digests = make(map[int64][sha256.Size]byte)
+	}
+	a.digests[packet.StreamPacketNumber] = sha256.Sum256(packet.Payload)
+	delete(a.digests, packet.StreamPacketNumber-history)
+}
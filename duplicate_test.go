@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestAssembler_Duplicates(t *testing.T) {
+	packet := func(n int, payload string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers: []string{
+				"stream-id: 1",
+				"stream-packet-number: " + strconv.Itoa(n),
+			},
+			Payload: []byte(payload),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		history int
+		dup     wrp.Message
+		wantErr error
+		want    DuplicateStats
+	}{
+		{
+			name: "buffered duplicate",
+			dup:  packet(2, "two"),
+			want: DuplicateStats{Buffered: 1},
+		}, {
+			name: "late duplicate",
+			dup:  packet(0, "zero"),
+			want: DuplicateStats{Late: 1},
+		}, {
+			name: "conflicting duplicates are dropped when not strict",
+			dup:  packet(2, "TWO"),
+			want: DuplicateStats{Buffered: 1},
+		}, {
+			name:   "strict buffered duplicate",
+			strict: true,
+			dup:    packet(2, "two"),
+			want:   DuplicateStats{Buffered: 1},
+		}, {
+			name:   "strict late duplicate",
+			strict: true,
+			dup:    packet(0, "zero"),
+			want:   DuplicateStats{Late: 1},
+		}, {
+			name:    "strict conflicting buffered duplicate",
+			strict:  true,
+			dup:     packet(2, "TWO"),
+			wantErr: ErrConflictingDuplicate,
+		}, {
+			name:    "strict conflicting late duplicate",
+			strict:  true,
+			dup:     packet(0, "ZERO"),
+			wantErr: ErrConflictingDuplicate,
+		}, {
+			name:    "strict late duplicate older than the history",
+			strict:  true,
+			history: 1,
+			dup:     packet(0, "ZERO"),
+			want:    DuplicateStats{Late: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r recorder
+			assembler := Assembler{
+				StrictDuplicates: tt.strict,
+				DuplicateHistory: tt.history,
+				Observer:         &r,
+			}
+
+			require.NoError(t, assembler.ProcessWRP(context.Background(), packet(0, "zero")))
+			require.NoError(t, assembler.ProcessWRP(context.Background(), packet(1, "one")))
+			require.NoError(t, assembler.ProcessWRP(context.Background(), packet(2, "two")))
+
+			buf := make([]byte, len("zeroone"))
+			_, err := io.ReadFull(&assembler, buf)
+			require.NoError(t, err)
+
+			err = assembler.ProcessWRP(context.Background(), tt.dup)
+			assert.Equal(t, tt.want, assembler.Duplicates())
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			require.Len(t, r.events, 1)
+			assert.Equal(t, EventConflictingDuplicate, r.events[0].Kind)
+			assert.ErrorIs(t, r.events[0].Err, tt.wantErr)
+
+			// The stream is not affected.
+			buf = make([]byte, len("two"))
+			_, err = io.ReadFull(&assembler, buf)
+			require.NoError(t, err)
+			assert.Equal(t, "two", string(buf))
+		})
+	}
+}
+
+func TestAssembler_DuplicateDigests(t *testing.T) {
+	assembler := Assembler{
+		StrictDuplicates: true,
+		DuplicateHistory: 2,
+	}
+
+	for i := range 5 {
+		packet := simpleStreamingMessage{StreamPacketNumber: int64(i)}
+		packet.Payload = []byte{byte(i)}
+		assembler.keepDigest(&packet)
+	}
+
+	assert.Len(t, assembler.digests, 2)
+	assert.Contains(t, assembler.digests, int64(3))
+	assert.Contains(t, assembler.digests, int64(4))
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// KeyProvider provides the keys used to encrypt and decrypt packet payloads.
+// The key is selected by the value of the stream-key-id header.  Keys must be
+// 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// Key returns the key for the key id.  If the key is not known, an error
+	// wrapping ErrUnknownKey should be returned.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider backed by a map of key ids to keys.
+type StaticKeys map[string][]byte
+
+var _ KeyProvider = StaticKeys(nil)
+
+// Key implements the KeyProvider interface.
+func (k StaticKeys) Key(id string) ([]byte, error) {
+	key, found := k[id]
+	if !found {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// newAEAD looks up the key for the id and creates an AES-GCM AEAD with it.
+func newAEAD(keys KeyProvider, id string) (cipher.AEAD, error) {
+	if keys == nil {
+		return nil, &unknownKey{id: id, err: fmt.Errorf("no key provider")}
+	}
+
+	key, err := keys.Key(id)
+	if err != nil {
+		return nil, &unknownKey{id: id, err: err}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidInput, id, err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// associatedData binds the stream id and packet number to the encrypted
+// payload so a packet cannot be moved to another stream or position.
+func associatedData(id string, number int64) []byte {
+	ad := make([]byte, 0, len(id)+1+8)
+	ad = append(ad, id...)
+	ad = append(ad, 0)
+	return binary.BigEndian.AppendUint64(ad, uint64(number)) // nolint:gosec
+}
+
+// seal encrypts the payload.  The random nonce is placed in front of the
+// encrypted payload.
+func seal(aead cipher.AEAD, id string, number int64, payload []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	_, _ = rand.Read(nonce)
+
+	return aead.Seal(nonce, nonce, payload, associatedData(id, number))
+}
+
+// open decrypts and authenticates a payload produced by seal.
+func open(aead cipher.AEAD, keyID, id string, number int64, payload []byte) ([]byte, error) {
+	if len(payload) < aead.NonceSize() {
+		return nil, &authenticationFailed{keyID: keyID, number: number}
+	}
+
+	nonce, sealed := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, associatedData(id, number))
+	if err != nil {
+		return nil, &authenticationFailed{keyID: keyID, number: number}
+	}
+
+	return plain, nil
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+var testKeys = StaticKeys{
+	"key-1": bytes.Repeat([]byte{0x01}, 32),
+	"key-2": bytes.Repeat([]byte{0x02}, 16),
+}
+
+// failingKeys is a KeyProvider that fails every lookup.
+type failingKeys struct{}
+
+func (failingKeys) Key(string) ([]byte, error) {
+	return nil, errors.New("key store unavailable")
+}
+
+func TestWithEncryption(t *testing.T) {
+	tests := []struct {
+		name  string
+		keyID string
+		keys  KeyProvider
+		err   error
+	}{
+		{
+			name:  "valid key",
+			keyID: "key-2",
+			keys:  testKeys,
+		}, {
+			name:  "unknown key",
+			keyID: "key-3",
+			keys:  testKeys,
+			err:   ErrUnknownKey,
+		}, {
+			name:  "invalid key id",
+			keyID: "key 1",
+			keys:  testKeys,
+			err:   ErrInvalidInput,
+		}, {
+			name: "empty key id",
+			keys: testKeys,
+			err:  ErrInvalidInput,
+		}, {
+			name:  "nil keys",
+			keyID: "key-1",
+			err:   ErrInvalidInput,
+		}, {
+			name:  "key lookup fails",
+			keyID: "key-1",
+			keys:  failingKeys{},
+			err:   ErrUnknownKey,
+		}, {
+			name:  "invalid key size",
+			keyID: "short",
+			keys:  StaticKeys{"short": []byte("too short")},
+			err:   ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(
+				ID("123"),
+				Reader(strings.NewReader("Hello")),
+				WithEncryption(tt.keyID, tt.keys),
+			)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, p)
+				return
+			}
+
+			assert.NoError(t, err)
+			require.NotNil(t, p)
+			assert.NotNil(t, p.aead)
+		})
+	}
+}
+
+func TestEncryption_EndToEnd(t *testing.T) {
+	input := "Hello, World!  This is an encrypted stream."
+
+	for _, encoding := range []Encoding{EncodingIdentity, EncodingGzip} {
+		t.Run(string(encoding), func(t *testing.T) {
+			msgs := packetize(t, input, WithEncryption("key-1", testKeys), WithEncoding(encoding))
+
+			for _, msg := range msgs {
+				assert.Contains(t, msg.Headers, "stream-key-id: key-1")
+				assert.NotContains(t, string(msg.Payload), "Hello")
+			}
+
+			var sent uint64
+			assembler := Assembler{Keys: testKeys}
+			for _, msg := range msgs {
+				sent += uint64(len(msg.Payload))
+				require.NoError(t, assembler.ProcessWRP(context.Background(), msg))
+			}
+
+			got, err := io.ReadAll(&assembler)
+			assert.NoError(t, err)
+			assert.Equal(t, input, string(got))
+			assert.Equal(t, sent, assembler.Progress().EncodedBytes)
+		})
+	}
+}
+
+func TestEncryption_Failures(t *testing.T) {
+	input := "Hello, World!"
+
+	tests := []struct {
+		name   string
+		keys   KeyProvider
+		modify func([]wrp.Message) []wrp.Message
+		err    error
+	}{
+		{
+			name: "no key provider",
+			err:  ErrUnknownKey,
+		}, {
+			name: "unknown key",
+			keys: StaticKeys{"key-2": testKeys["key-2"]},
+			err:  ErrUnknownKey,
+		}, {
+			name: "wrong key",
+			keys: StaticKeys{"key-1": testKeys["key-2"]},
+			err:  ErrAuthenticationFailed,
+		}, {
+			name: "tampered payload",
+			keys: testKeys,
+			modify: func(msgs []wrp.Message) []wrp.Message {
+				msgs[0].Payload[len(msgs[0].Payload)-1] ^= 0xff
+				return msgs
+			},
+			err: ErrAuthenticationFailed,
+		}, {
+			name: "truncated payload",
+			keys: testKeys,
+			modify: func(msgs []wrp.Message) []wrp.Message {
+				msgs[0].Payload = msgs[0].Payload[:4]
+				return msgs
+			},
+			err: ErrAuthenticationFailed,
+		}, {
+			name: "reordered packets",
+			keys: testKeys,
+			modify: func(msgs []wrp.Message) []wrp.Message {
+				msgs[0].Payload, msgs[1].Payload = msgs[1].Payload, msgs[0].Payload
+				return msgs
+			},
+			err: ErrAuthenticationFailed,
+		}, {
+			name: "unencrypted packet",
+			keys: testKeys,
+			modify: func(msgs []wrp.Message) []wrp.Message {
+				msgs[0].Headers = []string{
+					"stream-id: test",
+					"stream-packet-number: 0",
+				}
+				msgs[0].Payload = []byte("Hello")
+				return msgs
+			},
+			err: ErrAuthenticationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := packetize(t, input, WithEncryption("key-1", testKeys), WithEncoding(EncodingIdentity))
+			if tt.modify != nil {
+				msgs = tt.modify(msgs)
+			}
+
+			// The packet is rejected before it is buffered.
+			assembler := Assembler{Keys: tt.keys}
+			err := assembler.ProcessWRP(context.Background(), msgs[0])
+			assert.ErrorIs(t, err, tt.err)
+			assert.Empty(t, assembler.packets)
+		})
+	}
+}
+
+func TestEncryption_ForgedPacketFirst(t *testing.T) {
+	input := "Hello, World!"
+	msgs := packetize(t, input, WithEncryption("key-1", testKeys), WithEncoding(EncodingIdentity))
+
+	// A forged packet guesses the key id and packet number of the genuine one.
+	forged := msgs[0]
+	forged.Payload = make([]byte, len(msgs[0].Payload))
+
+	assembler := Assembler{Keys: testKeys, StrictDuplicates: true}
+	err := assembler.ProcessWRP(context.Background(), forged)
+	assert.ErrorIs(t, err, ErrAuthenticationFailed)
+
+	for _, msg := range msgs {
+		require.NoError(t, assembler.ProcessWRP(context.Background(), msg))
+	}
+
+	got, err := io.ReadAll(&assembler)
+	assert.NoError(t, err)
+	assert.Equal(t, input, string(got))
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 )
 
 var (
@@ -23,8 +24,171 @@ var (
 	// ErrNotAvailable is returned to indicate that the requested information is
 	// not available.
 	ErrNotAvailable = errors.New("information not available")
+
+	// ErrAborted is returned by the Packetizer once the stream has been
+	// aborted.
+	ErrAborted = errors.New("aborted")
+
+	// ErrCanceledByReceiver is returned when the receiver of the stream asked
+	// the producer to stop sending.
+	ErrCanceledByReceiver = errors.New("canceled by receiver")
+
+	// ErrUnknownKey is returned when the key for a stream-key-id is not
+	// available.
+	ErrUnknownKey = errors.New("unknown key")
+
+	// ErrAuthenticationFailed is returned when an encrypted packet cannot be
+	// authenticated, or when a packet is not encrypted but is required to be.
+	ErrAuthenticationFailed = errors.New("authentication failed")
+
+	// ErrInvalidSignature is returned when a packet signature is missing or
+	// does not verify.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrSummaryMismatch is returned when the stream-total-packets or
+	// stream-total-length of the final packet does not match the stream
+	// received.
+	ErrSummaryMismatch = errors.New("stream summary mismatch")
+
+	// ErrInconsistentEnvelope is returned when the WRP fields of the packets of
+	// a stream differ.
+	ErrInconsistentEnvelope = errors.New("inconsistent envelope")
+
+	// ErrBufferFull is returned when a packet cannot be buffered because the
+	// Assembler already holds as many packets or bytes as it is allowed to.
+	ErrBufferFull = errors.New("buffer full")
+
+	// ErrConflictingDuplicate is returned when a packet is received again with
+	// a different payload than the first time.
+	ErrConflictingDuplicate = errors.New("conflicting duplicate packet")
+
+	// ErrProtocolViolation is returned by an Assembler with Strict set when a
+	// packet breaks a rule of the protocol.  It is matched by a
+	// ProtocolViolationError along with the sentinel for the rule.
+	ErrProtocolViolation = errors.New("protocol violation")
+
+	// ErrMultipleFinalPackets is matched when more than one packet of a stream
+	// has a stream-final-packet header.
+	ErrMultipleFinalPackets = errors.New("multiple final packets")
+
+	// ErrPacketAfterFinal is matched when a packet is numbered after the final
+	// packet of the stream.
+	ErrPacketAfterFinal = errors.New("packet after the final packet")
+
+	// ErrEstimatedLengthDecreased is matched when the
+	// stream-estimated-total-length of a packet is less than the estimate of
+	// an earlier packet of the stream.
+	ErrEstimatedLengthDecreased = errors.New("estimated length decreased")
+
+	// ErrStreamCanceled is matched by a StreamError with the canceled code.
+	ErrStreamCanceled = errors.New("stream canceled")
+
+	// ErrStreamTimeout is matched by a StreamError with the timeout code.
+	ErrStreamTimeout = errors.New("stream timeout")
+
+	// ErrStreamIOError is matched by a StreamError with the io-error code.
+	ErrStreamIOError = errors.New("stream io error")
+
+	// ErrStreamAborted is matched by a StreamError with the aborted code.
+	ErrStreamAborted = errors.New("stream aborted")
 )
 
+var reasonErrors = map[ReasonCode]error{
+	ReasonCanceled: ErrStreamCanceled,
+	ReasonTimeout:  ErrStreamTimeout,
+	ReasonIOError:  ErrStreamIOError,
+	ReasonAborted:  ErrStreamAborted,
+}
+
+// StreamError is returned by the Assembler when the final packet indicates the
+// stream ended early.  It satisfies errors.Is(err, io.ErrUnexpectedEOF) as well
+// as the sentinel error matching the code of the Reason, if there is one.
+type StreamError struct {
+	Reason Reason
+}
+
+func (e *StreamError) Error() string {
+	if e.Reason.Code == "" {
+		return fmt.Sprintf("%s: %s", io.ErrUnexpectedEOF.Error(), e.Reason.Message)
+	}
+	return fmt.Sprintf("%s: %s", io.ErrUnexpectedEOF.Error(), e.Reason.String())
+}
+
+func (e *StreamError) Is(target error) bool {
+	if target == io.ErrUnexpectedEOF { // nolint:errorlint
+		return true
+	}
+
+	sentinel, found := reasonErrors[e.Reason.Code]
+	return found && target == sentinel // nolint:errorlint
+}
+
+func (e *StreamError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// SummaryMismatchError is returned by the Assembler when the summary headers of
+// the final packet do not match the stream that was received.  It satisfies
+// errors.Is(err, io.ErrUnexpectedEOF) as well as ErrSummaryMismatch.
+type SummaryMismatchError struct {
+	// Header is the summary header that did not match.
+	Header string
+
+	// Expected is the value of the header.
+	Expected uint64
+
+	// Actual is the value for the stream received.
+	Actual uint64
+}
+
+func (e *SummaryMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s is %d, received %d",
+		ErrSummaryMismatch.Error(), e.Header, e.Expected, e.Actual)
+}
+
+func (e *SummaryMismatchError) Is(target error) bool {
+	return target == ErrSummaryMismatch || target == io.ErrUnexpectedEOF // nolint:errorlint
+}
+
+func (e *SummaryMismatchError) Unwrap() []error {
+	return []error{
+		ErrSummaryMismatch,
+		io.ErrUnexpectedEOF,
+	}
+}
+
+// ProtocolViolationError is returned by an Assembler with Strict set when a
+// packet breaks a rule of the protocol.  It satisfies
+// errors.Is(err, ErrProtocolViolation) as well as the Violation.
+type ProtocolViolationError struct {
+	// Violation is the rule that was broken: ErrMultipleFinalPackets,
+	// ErrPacketAfterFinal or ErrEstimatedLengthDecreased.
+	Violation error
+
+	// PacketNumber is the number of the packet that broke the rule.
+	PacketNumber int64
+
+	// Conflict is the number of the packet it conflicts with: the final
+	// packet, or the packet with the estimate it is compared with.
+	Conflict int64
+}
+
+func (e *ProtocolViolationError) Error() string {
+	return fmt.Sprintf("%s: %s: packet %d conflicts with packet %d",
+		ErrProtocolViolation.Error(), e.Violation, e.PacketNumber, e.Conflict)
+}
+
+func (e *ProtocolViolationError) Is(target error) bool {
+	return target == ErrProtocolViolation || target == e.Violation // nolint:errorlint
+}
+
+func (e *ProtocolViolationError) Unwrap() []error {
+	return []error{
+		ErrProtocolViolation,
+		e.Violation,
+	}
+}
+
 type unexpectedEOF struct {
 	message    string
 	messageErr error
@@ -71,3 +235,175 @@ func (e *packetGapExceeded) Is(target error) bool {
 func (e *packetGapExceeded) Unwrap() error {
 	return ErrPacketGapExceeded
 }
+
+type unknownKey struct {
+	id  string
+	err error
+}
+
+func (e *unknownKey) Error() string {
+	if e.err == ErrUnknownKey { // nolint:errorlint
+		return fmt.Sprintf("%s: key id %q", ErrUnknownKey.Error(), e.id)
+	}
+	return fmt.Sprintf("%s: key id %q: %s", ErrUnknownKey.Error(), e.id, e.err)
+}
+
+func (e *unknownKey) Is(target error) bool {
+	return errors.Is(target, ErrUnknownKey)
+}
+
+func (e *unknownKey) Unwrap() []error {
+	return []error{
+		ErrUnknownKey,
+		e.err,
+	}
+}
+
+type authenticationFailed struct {
+	keyID  string
+	number int64
+}
+
+func (e *authenticationFailed) Error() string {
+	if e.keyID == "" {
+		return fmt.Sprintf("%s: packet %d is not encrypted",
+			ErrAuthenticationFailed.Error(), e.number)
+	}
+	return fmt.Sprintf("%s: packet %d with key id %q",
+		ErrAuthenticationFailed.Error(), e.number, e.keyID)
+}
+
+func (e *authenticationFailed) Is(target error) bool {
+	return errors.Is(target, ErrAuthenticationFailed)
+}
+
+func (e *authenticationFailed) Unwrap() error {
+	return ErrAuthenticationFailed
+}
+
+type invalidSignature struct {
+	number int64
+	reason string
+}
+
+func (e *invalidSignature) Error() string {
+	return fmt.Sprintf("%s: packet %d: %s", ErrInvalidSignature.Error(), e.number, e.reason)
+}
+
+func (e *invalidSignature) Is(target error) bool {
+	return errors.Is(target, ErrInvalidSignature)
+}
+
+func (e *invalidSignature) Unwrap() error {
+	return ErrInvalidSignature
+}
+
+type canceledByReceiver struct {
+	reason Reason
+}
+
+func (e *canceledByReceiver) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCanceledByReceiver.Error(), e.reason.String())
+}
+
+func (e *canceledByReceiver) Is(target error) bool {
+	return errors.Is(target, ErrCanceledByReceiver)
+}
+
+func (e *canceledByReceiver) Unwrap() error {
+	return ErrCanceledByReceiver
+}
+
+type inconsistentEnvelope struct {
+	number int64
+	field  string
+}
+
+func (e *inconsistentEnvelope) Error() string {
+	return fmt.Sprintf("%s: packet %d has a different %s than the earlier packets",
+		ErrInconsistentEnvelope.Error(), e.number, e.field)
+}
+
+func (e *inconsistentEnvelope) Is(target error) bool {
+	return errors.Is(target, ErrInconsistentEnvelope)
+}
+
+func (e *inconsistentEnvelope) Unwrap() error {
+	return ErrInconsistentEnvelope
+}
+
+type conflictingDuplicate struct {
+	number   int64
+	consumed bool
+}
+
+func (e *conflictingDuplicate) Error() string {
+	if e.consumed {
+		return fmt.Sprintf("%s: packet %d differs from the packet already read",
+			ErrConflictingDuplicate.Error(), e.number)
+	}
+	return fmt.Sprintf("%s: packet %d differs from the packet already received",
+		ErrConflictingDuplicate.Error(), e.number)
+}
+
+func (e *conflictingDuplicate) Is(target error) bool {
+	return errors.Is(target, ErrConflictingDuplicate)
+}
+
+func (e *conflictingDuplicate) Unwrap() error {
+	return ErrConflictingDuplicate
+}
+
+type bufferFull struct {
+	number int64
+	limit  string
+	max    int
+}
+
+func (e *bufferFull) Error() string {
+	return fmt.Sprintf("%s: packet %d would exceed the limit of %d buffered %s",
+		ErrBufferFull.Error(), e.number, e.max, e.limit)
+}
+
+func (e *bufferFull) Is(target error) bool {
+	return errors.Is(target, ErrBufferFull)
+}
+
+func (e *bufferFull) Unwrap() error {
+	return ErrBufferFull
+}
+
+type tooManyStreams struct {
+	max int
+}
+
+func (e *tooManyStreams) Error() string {
+	return fmt.Sprintf("%s: the limit of %d streams is reached", ErrBufferFull.Error(), e.max)
+}
+
+func (e *tooManyStreams) Is(target error) bool {
+	return errors.Is(target, ErrBufferFull)
+}
+
+func (e *tooManyStreams) Unwrap() error {
+	return ErrBufferFull
+}
+
+type idleTimeout struct {
+	timeout time.Duration
+}
+
+func (e *idleTimeout) Error() string {
+	return fmt.Sprintf("%s: no packet received for %s", ErrStreamTimeout.Error(), e.timeout)
+}
+
+func (e *idleTimeout) Is(target error) bool {
+	return target == ErrStreamTimeout || target == io.ErrUnexpectedEOF // nolint:errorlint
+}
+
+func (e *idleTimeout) Unwrap() []error {
+	return []error{
+		ErrStreamTimeout,
+		io.ErrUnexpectedEOF,
+	}
+}
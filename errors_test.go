@@ -33,3 +33,15 @@ func TestUnexpectedEOF_Unwrap(t *testing.T) {
 	unwrapped2 := err.Unwrap()
 	assert.Same(t, unwrapped[1], unwrapped2[1], "message error should be the same instance")
 }
+
+func TestProtocolViolationError(t *testing.T) {
+	err := &ProtocolViolationError{
+		Violation:    ErrPacketAfterFinal,
+		PacketNumber: 4,
+		Conflict:     2,
+	}
+	assert.Equal(t, "protocol violation: packet after the final packet: packet 4 conflicts with packet 2", err.Error())
+	assert.ErrorIs(t, err, ErrProtocolViolation)
+	assert.ErrorIs(t, err, ErrPacketAfterFinal)
+	assert.NotErrorIs(t, err, ErrMultipleFinalPackets)
+}
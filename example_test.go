@@ -69,6 +69,50 @@ func Example() {
 	// Output: This is an example of how to use the wrpssp package.
 }
 
+func Example_packets() {
+	sent := "This is an example of how to iterate over the packets."
+
+	packer, _ := wrpssp.New(
+		wrpssp.ID("123"),
+		wrpssp.Reader(strings.NewReader(sent)),
+		// Split the string into 10 byte packets for the example or there would
+		// only be one packet.  Normally this would be a much larger number.
+		wrpssp.MaxPacketSize(10),
+
+		// Normally this would be EncodingGzip, but for the example we are using
+		// EncodingIdentity so that the packets are not compressed.
+		wrpssp.WithEncoding(wrpssp.EncodingIdentity),
+	)
+
+	assembler := wrpssp.Assembler{}
+
+	ctx := context.Background()
+	template := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "self:",
+		Destination: "event:foo",
+	}
+
+	// Every packet yielded should be sent, including the final packet.  An
+	// error indicates the stream ended early or a packet could not be made.
+	for msg, err := range packer.Packets(ctx, template) {
+		if msg != nil {
+			// Normally the msg would be sent out over the wire, but for this
+			// example we are going to simply directly assemble the packets.
+			_ = assembler.ProcessWRP(ctx, *msg)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	buf, _ := io.ReadAll(&assembler)
+
+	fmt.Println(string(buf))
+
+	// Output: This is an example of how to iterate over the packets.
+}
+
 func Example_requestResponse() {
 	sent := "This is an example of how to use the wrpssp package with a request/response."
 
@@ -0,0 +1,298 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Follower is an io.ReadCloser that reads a file that is still being written,
+// similar to `tail -f`.  When the end of the file is reached, the Follower
+// waits for more data to be appended instead of returning io.EOF.  Truncation
+// and rotation (the path being replaced by a new file) are detected and the
+// reading continues with the new contents.
+//
+// The stream ends with io.EOF once Stop is called and all the data written so
+// far has been read, or once no new data has arrived for the inactivity
+// timeout.  This causes the Packetizer to emit the final packet with the
+// stream-final-packet marker of 'eof'.
+//
+// The Follower supports read deadlines, so a Packetizer reading from it can
+// be interrupted when the context passed to Next is canceled, including when
+// it is paired with the MaxLatency option since data is appended slowly.
+type Follower struct {
+	path       string
+	poll       time.Duration
+	inactivity time.Duration
+
+	file       *os.File
+	offset     int64
+	lastActive time.Time
+
+	m        sync.Mutex
+	stopped  bool
+	closed   bool
+	deadline time.Time
+	wake     chan struct{}
+}
+
+var _ io.ReadCloser = (*Follower)(nil)
+
+// FollowOption is a functional option for the Follower.
+type FollowOption interface {
+	apply(*Follower) error
+}
+
+type followOptionFunc func(*Follower) error
+
+func (f followOptionFunc) apply(follower *Follower) error {
+	return f(follower)
+}
+
+// FollowPollInterval sets how often the file is checked for new data once the
+// end of the file is reached.  This is optional.  If the interval is less
+// than 1, the default value of 250ms is used.
+func FollowPollInterval(d time.Duration) FollowOption {
+	return followOptionFunc(func(f *Follower) error {
+		if d < 1 {
+			d = 250 * time.Millisecond
+		}
+		f.poll = d
+		return nil
+	})
+}
+
+// FollowInactivityTimeout sets how long the Follower waits for new data before
+// ending the stream.  This is optional.  If the timeout is less than 1, the
+// stream only ends when Stop is called.
+func FollowInactivityTimeout(d time.Duration) FollowOption {
+	return followOptionFunc(func(f *Follower) error {
+		if d < 1 {
+			d = 0
+		}
+		f.inactivity = d
+		return nil
+	})
+}
+
+// Follow opens the file at path and returns a Follower that reads it from the
+// beginning.
+func Follow(path string, opts ...FollowOption) (*Follower, error) {
+	f := Follower{
+		path: path,
+		wake: make(chan struct{}, 1),
+	}
+
+	defaults := []FollowOption{
+		FollowPollInterval(0),
+		FollowInactivityTimeout(0),
+	}
+
+	opts = append(defaults, opts...)
+
+	for _, opt := range opts {
+		if err := opt.apply(&f); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	f.file = file
+	f.lastActive = time.Now()
+
+	return &f, nil
+}
+
+// Read implements the io.Reader interface.  Read blocks until data is
+// available, the stream ends, or the read deadline passes.
+func (f *Follower) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		stopped, err := f.state()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := f.file.Read(p)
+		if n > 0 {
+			f.offset += int64(n)
+			f.lastActive = time.Now()
+			return n, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		// The end of the current file has been reached.
+		changed, err := f.reopen()
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			continue
+		}
+
+		// Only end once all the data written before Stop was called is read.
+		if stopped {
+			return 0, io.EOF
+		}
+
+		wait := f.poll
+		if f.inactivity > 0 {
+			idle := time.Since(f.lastActive)
+			if idle >= f.inactivity {
+				return 0, io.EOF
+			}
+			wait = min(wait, f.inactivity-idle)
+		}
+
+		f.sleep(wait)
+	}
+}
+
+// state returns if the Follower has been stopped, or an error if it is closed
+// or the read deadline has passed.
+func (f *Follower) state() (bool, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.closed {
+		return false, ErrClosed
+	}
+
+	if !f.deadline.IsZero() && !time.Now().Before(f.deadline) {
+		return false, os.ErrDeadlineExceeded
+	}
+
+	return f.stopped, nil
+}
+
+// sleep waits for the duration, or until woken by Stop, Close or a change to
+// the read deadline.
+func (f *Follower) sleep(d time.Duration) {
+	f.m.Lock()
+	if !f.deadline.IsZero() {
+		d = min(d, time.Until(f.deadline))
+	}
+	f.m.Unlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-f.wake:
+	}
+}
+
+// reopen checks if the file has been truncated or rotated.  If the file was
+// truncated, reading restarts from the beginning.  If the path now refers to
+// a different file, the rest of the old file is read first, then the new file
+// is opened and read from the beginning.  The return value is true if there
+// may be more data to read.
+func (f *Follower) reopen() (bool, error) {
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	latest, err := os.Stat(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// The file is being rotated; wait for the new file.
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !os.SameFile(current, latest) {
+		// Data may have been appended to the old file after the last read
+		// and before it was rotated; read it before switching.
+		if current.Size() > f.offset {
+			return true, nil
+		}
+
+		file, err := os.Open(f.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		_ = f.file.Close()
+		f.file = file
+		f.offset = 0
+		return true, nil
+	}
+
+	if current.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.offset = 0
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// SetReadDeadline sets the deadline for Read calls.  A zero value for t means
+// Read will not time out.  A Read blocked waiting for data returns
+// os.ErrDeadlineExceeded once the deadline passes.
+func (f *Follower) SetReadDeadline(t time.Time) error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.deadline = t
+	f.signal()
+
+	return nil
+}
+
+// Stop ends the stream once all the data written to the file so far has been
+// read.  Stop is safe to call concurrently with Read.
+func (f *Follower) Stop() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.stopped = true
+	f.signal()
+}
+
+// Close closes the file and implements the io.Closer interface.  Close should
+// not be called concurrently with Read; use Stop to end the stream instead.
+func (f *Follower) Close() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.closed {
+		return nil
+	}
+
+	f.closed = true
+	f.signal()
+
+	return f.file.Close()
+}
+
+// signal wakes a Read that is waiting for data (must be called with lock held)
+func (f *Follower) signal() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
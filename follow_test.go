@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+// readN reads exactly n bytes from the Follower.
+func readN(t *testing.T, f *Follower, n int) string {
+	t.Helper()
+
+	buf := make([]byte, n)
+	_, err := io.ReadFull(f, buf)
+	require.NoError(t, err)
+
+	return string(buf)
+}
+
+func TestFollow(t *testing.T) {
+	_, err := Follow(filepath.Join(t.TempDir(), "missing.log"))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "")
+
+	f, err := Follow(path)
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, f.poll)
+	assert.Zero(t, f.inactivity)
+	assert.NoError(t, f.Close())
+	assert.NoError(t, f.Close())
+
+	n, err := f.Read(make([]byte, 1))
+	assert.Equal(t, 0, n)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestFollower_Read(t *testing.T) {
+	t.Run("appended data is read until stopped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "Hello")
+
+		f, err := Follow(path, FollowPollInterval(time.Millisecond))
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, "Hello", readN(t, f, 5))
+
+		appendFile(t, path, ", World")
+		assert.Equal(t, ", World", readN(t, f, 7))
+
+		appendFile(t, path, "!")
+		f.Stop()
+
+		got, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, "!", string(got))
+	})
+
+	t.Run("truncation restarts from the beginning", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "Hello, World")
+
+		f, err := Follow(path, FollowPollInterval(time.Millisecond))
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, "Hello, World", readN(t, f, 12))
+
+		require.NoError(t, os.Truncate(path, 0))
+		appendFile(t, path, "Bye")
+		assert.Equal(t, "Bye", readN(t, f, 3))
+	})
+
+	t.Run("rotation switches to the new file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendFile(t, path, "old")
+
+		f, err := Follow(path, FollowPollInterval(time.Millisecond))
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, "old", readN(t, f, 3))
+
+		require.NoError(t, os.Rename(path, filepath.Join(dir, "app.log.1")))
+		appendFile(t, path, "new")
+		assert.Equal(t, "new", readN(t, f, 3))
+	})
+
+	t.Run("data appended right before rotation is read", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendFile(t, path, "old")
+
+		f, err := Follow(path, FollowPollInterval(time.Millisecond))
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, "old", readN(t, f, 3))
+
+		// The end of the old file was reached just before the last write
+		// and the rotation.
+		appendFile(t, path, "tail")
+		require.NoError(t, os.Rename(path, filepath.Join(dir, "app.log.1")))
+		appendFile(t, path, "new")
+
+		changed, err := f.reopen()
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		assert.Equal(t, "tailnew", readN(t, f, 7))
+	})
+
+	t.Run("inactivity ends the stream", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "Hello")
+
+		f, err := Follow(path,
+			FollowPollInterval(time.Millisecond),
+			FollowInactivityTimeout(20*time.Millisecond),
+		)
+		require.NoError(t, err)
+		defer f.Close()
+
+		got, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", string(got))
+	})
+
+	t.Run("read deadline interrupts waiting", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "")
+
+		f, err := Follow(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		require.NoError(t, f.SetReadDeadline(time.Now().Add(10*time.Millisecond)))
+
+		n, err := f.Read(make([]byte, 10))
+		assert.Equal(t, 0, n)
+		assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	})
+}
+
+func TestFollower_Packetizer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "line 1\n")
+
+	f, err := Follow(path, FollowPollInterval(time.Millisecond))
+	require.NoError(t, err)
+	defer f.Close()
+
+	packetizer, err := New(
+		ID("follow"),
+		Reader(f),
+		MaxPacketSize(7),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	got, err := packetizer.Next(context.Background(), in)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("line 1\n"), got.Payload)
+
+	appendFile(t, path, "line 2\n")
+	f.Stop()
+
+	got, err = packetizer.Next(context.Background(), in)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("line 2\n"), got.Payload)
+
+	got, err = packetizer.Next(context.Background(), in)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NotNil(t, got)
+	assert.Empty(t, got.Payload)
+	assert.Contains(t, got.Headers, "stream-final-packet: eof")
+}
+
+func TestFollower_PacketizerCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "line 1\n")
+
+	f, err := Follow(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	packetizer, err := New(
+		ID("follow"),
+		Reader(f),
+		MaxPacketSize(100),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	got, err := packetizer.Next(ctx, in)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotNil(t, got)
+	assert.Equal(t, []byte("line 1\n"), got.Payload)
+	assert.Contains(t, got.Headers, "stream-final-packet: timeout")
+}
+
+// returnedFollower closes returned once a Read of the Follower fails.
+type returnedFollower struct {
+	*Follower
+	once     sync.Once
+	returned chan struct{}
+}
+
+func (f *returnedFollower) Read(p []byte) (int, error) {
+	n, err := f.Follower.Read(p)
+	if err != nil {
+		f.once.Do(func() { close(f.returned) })
+	}
+	return n, err
+}
+
+func TestFollower_PacketizerMaxLatencyCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "line 1\n")
+
+	follower, err := Follow(path)
+	require.NoError(t, err)
+	defer follower.Close()
+
+	f := returnedFollower{Follower: follower, returned: make(chan struct{})}
+
+	packetizer, err := New(
+		ID("follow"),
+		Reader(&f),
+		MaxPacketSize(100),
+		MaxLatency(time.Hour),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	got, err := packetizer.Next(ctx, in)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotNil(t, got)
+	assert.Equal(t, []byte("line 1\n"), got.Payload)
+
+	// The read blocked waiting for more data is interrupted.
+	select {
+	case <-f.returned:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the blocked read was not interrupted")
+	}
+}
@@ -0,0 +1,399 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+const (
+	// These are the string literals of the headers used to carry the fields of
+	// a fragmented message that a Simple Event does not have.
+	stream_original_type   = "stream-original-type"
+	stream_original_accept = "stream-original-accept"
+	stream_original_status = "stream-original-status"
+	stream_original_rdr    = "stream-original-rdr"
+	stream_original_path   = "stream-original-path"
+)
+
+// Fragmenter is a wrp.Processor that sends messages to the next processor,
+// splitting messages with oversized payloads into SSP packets.  Messages with
+// a payload no larger than the maximum payload size, and messages that are
+// already SSP packets, are passed through untouched.
+//
+// Each fragmented message is sent as a new stream with a random stream ID.
+// The packets are Simple Events carrying the fields of the original message,
+// and the original message type and the fields a Simple Event does not have
+// are sent in stream-original-* headers.  A Defragmenter restores the
+// original message.
+type Fragmenter struct {
+	next    wrp.Processor
+	maxSize int
+	opts    []Option
+}
+
+var _ wrp.Processor = (*Fragmenter)(nil)
+
+// FragmentOption is a functional option for the Fragmenter.
+type FragmentOption interface {
+	apply(*Fragmenter) error
+}
+
+type fragmentOptionFunc func(*Fragmenter) error
+
+func (f fragmentOptionFunc) apply(fragmenter *Fragmenter) error {
+	return f(fragmenter)
+}
+
+// FragmentMaxPayloadSize sets the largest payload sent without fragmenting the
+// message, and the maximum payload size of each packet as sent, after it is
+// encoded and encrypted.  This is optional.  If the size is less than 1, the
+// default value of 64KB is used.
+func FragmentMaxPayloadSize(size int) FragmentOption {
+	return fragmentOptionFunc(func(f *Fragmenter) error {
+		if size < 1 {
+			size = 64 * 1024
+		}
+		f.maxSize = size
+		return nil
+	})
+}
+
+// FragmentPacketizerOptions adds options used to create the Packetizer for
+// each fragmented message, such as WithEncoding or WithSigner.  This is
+// optional.  The ID, Reader, MaxPacketSize and EstimatedLength are set by the
+// Fragmenter.
+func FragmentPacketizerOptions(opts ...Option) FragmentOption {
+	return fragmentOptionFunc(func(f *Fragmenter) error {
+		f.opts = append(f.opts, opts...)
+		return nil
+	})
+}
+
+// NewFragmenter creates a new Fragmenter that sends messages and packets to
+// next.
+func NewFragmenter(next wrp.Processor, opts ...FragmentOption) (*Fragmenter, error) {
+	if next == nil {
+		return nil, fmt.Errorf("%w: next processor must not be nil", ErrInvalidInput)
+	}
+
+	f := Fragmenter{
+		next: next,
+	}
+
+	opts = append([]FragmentOption{FragmentMaxPayloadSize(0)}, opts...)
+	for _, opt := range opts {
+		if err := opt.apply(&f); err != nil {
+			return nil, err
+		}
+	}
+
+	return &f, nil
+}
+
+// ProcessWRP implements the wrp.Processor interface.  The error returned by
+// the next processor for any packet stops the fragmentation and is returned.
+func (f *Fragmenter) ProcessWRP(ctx context.Context, msg wrp.Message) error {
+	if len(msg.Payload) <= f.maxSize || Is(&msg, wrp.NoStandardValidation()) {
+		return f.next.ProcessWRP(ctx, msg)
+	}
+
+	opts := []Option{
+		ID(rand.Text()),
+		Reader(bytes.NewReader(msg.Payload)),
+		MaxPacketSize(f.maxSize),
+		EstimatedLength(int64(len(msg.Payload))),
+	}
+	opts = append(opts, f.opts...)
+	opts = append(opts, maxPayloadSize(f.maxSize))
+
+	packetizer, err := New(opts...)
+	if err != nil {
+		return err
+	}
+
+	for packet, err := range packetizer.Packets(ctx, fragmentEnvelope(msg)) {
+		if err != nil {
+			return err
+		}
+
+		if err := f.next.ProcessWRP(ctx, *packet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fragmentEnvelope returns the Simple Event used as the envelope of each
+// packet of the message.
+func fragmentEnvelope(msg wrp.Message) wrp.Message {
+	headers := make([]string, 0, len(msg.Headers)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers, stream_original_type+": "+strconv.FormatInt(int64(msg.Type), 10))
+
+	if msg.Accept != "" {
+		headers = append(headers, stream_original_accept+": "+msg.Accept)
+	}
+	if msg.Status != nil {
+		headers = append(headers, stream_original_status+": "+strconv.FormatInt(*msg.Status, 10))
+	}
+	if msg.RequestDeliveryResponse != nil {
+		headers = append(headers, stream_original_rdr+": "+strconv.FormatInt(*msg.RequestDeliveryResponse, 10))
+	}
+	if msg.Path != "" {
+		headers = append(headers, stream_original_path+": "+msg.Path)
+	}
+
+	msg.Type = wrp.SimpleEventMessageType
+	msg.Headers = headers
+	msg.Accept = ""
+	msg.Status = nil
+	msg.RequestDeliveryResponse = nil
+	msg.Path = ""
+	msg.Payload = nil
+
+	return msg
+}
+
+// Defragmenter is a wrp.Processor that reassembles the messages fragmented by
+// a Fragmenter and sends the original messages to the next processor.  All
+// other messages, including SSP packets of other streams, are passed through
+// untouched.
+//
+// Packets are buffered until every packet of the message has arrived.  A
+// message with no packets received for the timeout is dropped.  Since the
+// packets may come from untrusted sources, the number of messages being
+// reassembled and the packets buffered for each should be limited with
+// DefragmentMaxStreams and DefragmentAssemblerOptions.
+type Defragmenter struct {
+	next       wrp.Processor
+	timeout    time.Duration
+	maxStreams int
+	assembler  []AssemblerOption
+
+	m       sync.Mutex
+	streams map[string]*fragments
+}
+
+type fragments struct {
+	assembler *Assembler
+	last      time.Time
+}
+
+var _ wrp.Processor = (*Defragmenter)(nil)
+
+// DefragmentOption is a functional option for the Defragmenter.
+type DefragmentOption interface {
+	apply(*Defragmenter) error
+}
+
+type defragmentOptionFunc func(*Defragmenter) error
+
+func (f defragmentOptionFunc) apply(defragmenter *Defragmenter) error {
+	return f(defragmenter)
+}
+
+// DefragmentTimeout sets how long a partially received message is kept after
+// its last packet arrived.  This is optional.  If the timeout is less than 1,
+// the default value of 1 minute is used.
+func DefragmentTimeout(d time.Duration) DefragmentOption {
+	return defragmentOptionFunc(func(f *Defragmenter) error {
+		if d < 1 {
+			d = time.Minute
+		}
+		f.timeout = d
+		return nil
+	})
+}
+
+// DefragmentMaxStreams sets the most messages reassembled at the same time.
+// The first packet of another message is rejected with an error matching
+// ErrBufferFull.  This is optional.  The limit must not be negative; 0 means
+// unlimited.
+func DefragmentMaxStreams(n int) DefragmentOption {
+	return defragmentOptionFunc(func(f *Defragmenter) error {
+		if n < 0 {
+			return fmt.Errorf("%w: max streams must not be negative", ErrInvalidInput)
+		}
+		f.maxStreams = n
+		return nil
+	})
+}
+
+// DefragmentAssemblerOptions adds the options used to create the Assembler of
+// each message, such as AssemblerMaxBufferedPackets and
+// AssemblerMaxBufferedBytes.  Options that select a single stream, such as
+// AssemblerStreamID, should not be used.  This is optional.
+func DefragmentAssemblerOptions(opts ...AssemblerOption) DefragmentOption {
+	return defragmentOptionFunc(func(f *Defragmenter) error {
+		f.assembler = append(f.assembler, opts...)
+		return nil
+	})
+}
+
+// NewDefragmenter creates a new Defragmenter that sends messages to next.
+func NewDefragmenter(next wrp.Processor, opts ...DefragmentOption) (*Defragmenter, error) {
+	if next == nil {
+		return nil, fmt.Errorf("%w: next processor must not be nil", ErrInvalidInput)
+	}
+
+	f := Defragmenter{
+		next:    next,
+		streams: make(map[string]*fragments),
+	}
+
+	opts = append([]DefragmentOption{DefragmentTimeout(0)}, opts...)
+	for _, opt := range opts {
+		if err := opt.apply(&f); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the assembler options once, instead of for every message.
+	if _, err := NewAssembler(f.assembler...); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// ProcessWRP implements the wrp.Processor interface.  Once the last missing
+// packet of a message arrives, the original message is sent to the next
+// processor and its error is returned.
+func (f *Defragmenter) ProcessWRP(ctx context.Context, msg wrp.Message) error {
+	id, err := GetStreamID(msg)
+	if err != nil || !isFragment(msg) {
+		return f.next.ProcessWRP(ctx, msg)
+	}
+
+	key := msg.Source + "\x00" + id
+	now := time.Now()
+
+	f.m.Lock()
+	for k, s := range f.streams {
+		if now.Sub(s.last) > f.timeout {
+			delete(f.streams, k)
+		}
+	}
+
+	stream, found := f.streams[key]
+	if !found {
+		if f.maxStreams > 0 && len(f.streams) >= f.maxStreams {
+			f.m.Unlock()
+			return &tooManyStreams{max: f.maxStreams}
+		}
+
+		assembler, err := NewAssembler(f.assembler...)
+		if err != nil {
+			f.m.Unlock()
+			return err
+		}
+
+		stream = &fragments{assembler: assembler}
+		f.streams[key] = stream
+	}
+	stream.last = now
+	f.m.Unlock()
+
+	if err := stream.assembler.ProcessWRP(ctx, msg); err != nil {
+		f.remove(key, stream)
+		return err
+	}
+
+	// Only the caller that removes the stream assembles it.
+	if !stream.assembler.complete() || !f.remove(key, stream) {
+		return nil
+	}
+
+	full, err := stream.assembler.AssembleMessage(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := restoreFragmented(full); err != nil {
+		return err
+	}
+
+	return f.next.ProcessWRP(ctx, *full)
+}
+
+// remove removes the stream if it is still being tracked, and reports if it
+// was.
+func (f *Defragmenter) remove(key string, stream *fragments) bool {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.streams[key] != stream {
+		return false
+	}
+
+	delete(f.streams, key)
+	return true
+}
+
+// isFragment reports if the message is a packet of a message fragmented by a
+// Fragmenter.
+func isFragment(msg wrp.Message) bool {
+	for _, header := range msg.Headers {
+		key, _, found := strings.Cut(header, ":")
+		if found && strings.ToLower(strings.TrimSpace(key)) == stream_original_type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// restoreFragmented restores the message type and fields of the reassembled
+// message from the stream-original-* headers, and removes them.
+func restoreFragmented(msg *wrp.Message) error {
+	others := make([]string, 0, len(msg.Headers))
+	for _, header := range msg.Headers {
+		key, value, _ := strings.Cut(header, ":")
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case stream_original_type:
+			var t int64
+			t, err = strconv.ParseInt(value, 10, 64)
+			msg.Type = wrp.MessageType(t)
+		case stream_original_accept:
+			msg.Accept = value
+		case stream_original_status:
+			var status int64
+			status, err = strconv.ParseInt(value, 10, 64)
+			msg.Status = &status
+		case stream_original_rdr:
+			var rdr int64
+			rdr, err = strconv.ParseInt(value, 10, 64)
+			msg.RequestDeliveryResponse = &rdr
+		case stream_original_path:
+			msg.Path = value
+		default:
+			others = append(others, header)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: header %q: %w", ErrInvalidInput, header, err)
+		}
+	}
+
+	msg.Headers = nil
+	if len(others) > 0 {
+		msg.Headers = others
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// collector is a wrp.Processor that records the messages it processes.
+type collector struct {
+	msgs []wrp.Message
+	err  error
+}
+
+func (c *collector) ProcessWRP(_ context.Context, msg wrp.Message) error {
+	c.msgs = append(c.msgs, msg)
+	return c.err
+}
+
+func TestNewFragmenter(t *testing.T) {
+	f, err := NewFragmenter(nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, f)
+
+	f, err = NewFragmenter(&collector{})
+	require.NoError(t, err)
+	assert.Equal(t, 64*1024, f.maxSize)
+
+	d, err := NewDefragmenter(nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, d)
+
+	d, err = NewDefragmenter(&collector{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, d.timeout)
+	assert.Zero(t, d.maxStreams)
+
+	d, err = NewDefragmenter(&collector{}, DefragmentMaxStreams(-1))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, d)
+
+	d, err = NewDefragmenter(&collector{}, DefragmentAssemblerOptions(AssemblerMaxBufferedPackets(-1)))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, d)
+}
+
+func TestFragmenter(t *testing.T) {
+	status := int64(200)
+	rdr := int64(1)
+	large := wrp.Message{
+		Type:                    wrp.SimpleRequestResponseMessageType,
+		Source:                  "dns:talaria.example.com",
+		Destination:             "mac:112233445566/config",
+		TransactionUUID:         "tx-1",
+		ContentType:             "application/json",
+		Accept:                  "application/json",
+		Status:                  &status,
+		RequestDeliveryResponse: &rdr,
+		Headers:                 []string{"app: config"},
+		Metadata:                map[string]string{"/boot-time": "1234"},
+		Path:                    "/config/a:b",
+		PartnerIDs:              []string{"comcast"},
+		Payload:                 bytes.Repeat([]byte("0123456789"), 10),
+	}
+
+	t.Run("small messages pass through", func(t *testing.T) {
+		var sent collector
+		f, err := NewFragmenter(&sent, FragmentMaxPayloadSize(100))
+		require.NoError(t, err)
+
+		require.NoError(t, f.ProcessWRP(context.Background(), large))
+		assert.Equal(t, []wrp.Message{large}, sent.msgs)
+	})
+
+	t.Run("SSP packets pass through", func(t *testing.T) {
+		var sent collector
+		f, err := NewFragmenter(&sent, FragmentMaxPayloadSize(10))
+		require.NoError(t, err)
+
+		packet := wrp.Message{
+			Type:    wrp.SimpleEventMessageType,
+			Headers: []string{"stream-id: 1", "stream-packet-number: 0"},
+			Payload: large.Payload,
+		}
+		require.NoError(t, f.ProcessWRP(context.Background(), packet))
+		assert.Equal(t, []wrp.Message{packet}, sent.msgs)
+	})
+
+	t.Run("oversized messages are fragmented and restored", func(t *testing.T) {
+		var sent collector
+		f, err := NewFragmenter(&sent,
+			FragmentMaxPayloadSize(30),
+			FragmentPacketizerOptions(WithEncoding(EncodingIdentity)),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, f.ProcessWRP(context.Background(), large))
+		require.Len(t, sent.msgs, 4)
+
+		id, err := GetStreamID(sent.msgs[0])
+		require.NoError(t, err)
+		for _, packet := range sent.msgs {
+			assert.Equal(t, wrp.SimpleEventMessageType, packet.Type)
+			assert.LessOrEqual(t, len(packet.Payload), 30)
+			assert.Contains(t, packet.Headers, "stream-original-type: 3")
+			assert.Contains(t, packet.Headers, "stream-estimated-total-length: 100")
+			assert.Equal(t, large.Source, packet.Source)
+			assert.Empty(t, packet.Accept)
+			assert.Nil(t, packet.Status)
+
+			got, err := GetStreamID(packet)
+			assert.NoError(t, err)
+			assert.Equal(t, id, got)
+		}
+
+		// Deliver the packets out of order and duplicated.
+		var received collector
+		d, err := NewDefragmenter(&received)
+		require.NoError(t, err)
+
+		for _, i := range []int{2, 0, 3, 0, 1} {
+			require.NoError(t, d.ProcessWRP(context.Background(), sent.msgs[i]))
+		}
+
+		require.Len(t, received.msgs, 1)
+		assert.Equal(t, large, received.msgs[0])
+		assert.Empty(t, d.streams)
+	})
+
+	t.Run("packet payloads stay within the limit", func(t *testing.T) {
+		// Random data does not compress, so encoding makes it larger.
+		random := large
+		random.Payload = make([]byte, 10000)
+		_, _ = rand.Read(random.Payload)
+
+		tests := []struct {
+			name      string
+			opts      []Option
+			encrypted bool
+		}{
+			{
+				name: "gzip",
+			}, {
+				name: "gzip huffman only",
+				opts: []Option{WithEncoding(EncodingGzipHuffmanOnly)},
+			}, {
+				name:      "gzip encrypted",
+				opts:      []Option{WithEncryption("key-1", testKeys)},
+				encrypted: true,
+			}, {
+				name: "identity encrypted",
+				opts: []Option{
+					WithEncoding(EncodingIdentity),
+					WithEncryption("key-2", testKeys),
+				},
+				encrypted: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var sent collector
+				f, err := NewFragmenter(&sent,
+					FragmentMaxPayloadSize(1000),
+					FragmentPacketizerOptions(tt.opts...),
+				)
+				require.NoError(t, err)
+
+				require.NoError(t, f.ProcessWRP(context.Background(), random))
+				for _, packet := range sent.msgs {
+					assert.LessOrEqual(t, len(packet.Payload), 1000)
+				}
+
+				var opts []DefragmentOption
+				if tt.encrypted {
+					opts = append(opts, DefragmentAssemblerOptions(AssemblerKeys(testKeys)))
+				}
+
+				var received collector
+				d, err := NewDefragmenter(&received, opts...)
+				require.NoError(t, err)
+				for _, packet := range sent.msgs {
+					require.NoError(t, d.ProcessWRP(context.Background(), packet))
+				}
+
+				require.Len(t, received.msgs, 1)
+				assert.Equal(t, random, received.msgs[0])
+			})
+		}
+	})
+
+	t.Run("the limit leaves no room for encrypted data", func(t *testing.T) {
+		var sent collector
+		f, err := NewFragmenter(&sent,
+			FragmentMaxPayloadSize(20),
+			FragmentPacketizerOptions(WithEncryption("key-1", testKeys)),
+		)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, f.ProcessWRP(context.Background(), large), ErrInvalidInput)
+		assert.Empty(t, sent.msgs)
+	})
+
+	t.Run("next processor errors are returned", func(t *testing.T) {
+		errSend := errors.New("send failed")
+		sent := collector{err: errSend}
+		f, err := NewFragmenter(&sent, FragmentMaxPayloadSize(30))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, f.ProcessWRP(context.Background(), large), errSend)
+		assert.Len(t, sent.msgs, 1)
+	})
+}
+
+func TestDefragmenter(t *testing.T) {
+	t.Run("other messages pass through", func(t *testing.T) {
+		var received collector
+		d, err := NewDefragmenter(&received)
+		require.NoError(t, err)
+
+		msgs := []wrp.Message{
+			{
+				Type:    wrp.SimpleRequestResponseMessageType,
+				Payload: []byte("not a packet"),
+			}, {
+				Type:    wrp.SimpleEventMessageType,
+				Headers: []string{"stream-id: 1", "stream-packet-number: 0"},
+				Payload: []byte("not fragmented"),
+			},
+		}
+		for _, msg := range msgs {
+			require.NoError(t, d.ProcessWRP(context.Background(), msg))
+		}
+
+		assert.Equal(t, msgs, received.msgs)
+		assert.Empty(t, d.streams)
+	})
+
+	fragment := func(source, id, n string, final ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      source,
+			Destination: "event:test",
+			Headers:     append([]string{"stream-id: " + id, "stream-packet-number: " + n, "stream-original-type: 4"}, final...),
+			Payload:     []byte("data"),
+		}
+	}
+
+	t.Run("streams are limited", func(t *testing.T) {
+		var received collector
+		d, err := NewDefragmenter(&received, DefragmentMaxStreams(2))
+		require.NoError(t, err)
+
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "1", "0")))
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "2", "0")))
+
+		err = d.ProcessWRP(context.Background(), fragment("mac:665544332211", "1", "0"))
+		assert.ErrorIs(t, err, ErrBufferFull)
+		assert.ErrorContains(t, err, "the limit of 2 streams is reached")
+		assert.Len(t, d.streams, 2)
+
+		// Packets of the streams already tracked are still accepted, and a
+		// completed stream makes room for another.
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "1", "1", "stream-final-packet: eof")))
+		assert.Len(t, received.msgs, 1)
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:665544332211", "1", "0")))
+		assert.Len(t, d.streams, 2)
+	})
+
+	t.Run("assembler options are used for each stream", func(t *testing.T) {
+		var received collector
+		d, err := NewDefragmenter(&received, DefragmentAssemblerOptions(AssemblerMaxBufferedPackets(2)))
+		require.NoError(t, err)
+
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "1", "1")))
+		require.NoError(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "1", "2")))
+
+		// The stream is dropped once it holds too many packets.
+		assert.ErrorIs(t, d.ProcessWRP(context.Background(), fragment("mac:112233445566", "1", "3")), ErrBufferFull)
+		assert.Empty(t, d.streams)
+		assert.Empty(t, received.msgs)
+	})
+
+	t.Run("stale messages are dropped", func(t *testing.T) {
+		var received collector
+		d, err := NewDefragmenter(&received, DefragmentTimeout(time.Millisecond))
+		require.NoError(t, err)
+
+		packet := func(n string, final ...string) wrp.Message {
+			return wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers:     append([]string{"stream-id: 1", "stream-packet-number: " + n, "stream-original-type: 4"}, final...),
+				Payload:     []byte("data"),
+			}
+		}
+
+		require.NoError(t, d.ProcessWRP(context.Background(), packet("0")))
+		assert.Len(t, d.streams, 1)
+
+		time.Sleep(5 * time.Millisecond)
+
+		// The first packet was dropped, so the message is never complete.
+		require.NoError(t, d.ProcessWRP(context.Background(), packet("1", "stream-final-packet: eof")))
+		assert.Len(t, d.streams, 1)
+		assert.Empty(t, received.msgs)
+	})
+}
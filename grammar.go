@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// validNumber matches '0' | [1-9][0-9]*.
+	validNumber = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+	// validBase64 matches standard base64 with padding.
+	validBase64 = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+)
+
+// ValidateHeaders checks the SSP headers against the grammar in protocol.md
+// exactly.  Labels and the enumerated values are case-insensitive and the
+// whitespace around labels and values is ignored.  Headers that are not SSP
+// headers are ignored.  The error describes the first header that does not
+// conform, and matches ErrInvalidInput.
+func ValidateHeaders(headers []string) error {
+	_, _, err := splitStrict(headers)
+	return err
+}
+
+// splitStrict is split, but each SSP header is checked against the grammar in
+// protocol.md and may only be present once.  The enumerated values are
+// returned in lowercase.
+func splitStrict(headers []string) (map[string]string, []string, error) {
+	result := make(map[string]string, len(headers))
+	others := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		label, value, found := strings.Cut(header, ":")
+		if !found {
+			others = append(others, header)
+			continue
+		}
+
+		label = strings.ToLower(strings.TrimSpace(label))
+		if !isControlLabel(label) {
+			others = append(others, header)
+			continue
+		}
+
+		if _, found := result[label]; found {
+			return nil, nil, fmt.Errorf("%w: %s: header is present more than once", ErrInvalidInput, label)
+		}
+
+		value, err := checkGrammar(label, strings.TrimSpace(value))
+		if err != nil {
+			return nil, nil, err
+		}
+		result[label] = value
+	}
+
+	return result, others, nil
+}
+
+// checkGrammar checks the value of the header with the label against the
+// grammar in protocol.md, and returns the normalized value.
+func checkGrammar(label, value string) (string, error) {
+	invalid := func(format string, args ...any) (string, error) {
+		return "", fmt.Errorf("%w: %s: %q %s", ErrInvalidInput, label, value, fmt.Sprintf(format, args...))
+	}
+
+	switch label {
+	case stream_id, stream_key_id:
+		if !validID.MatchString(value) {
+			return invalid("is not an identifier of letters, digits, '_' and '-'")
+		}
+	case stream_packet_number, stream_total_length:
+		if !validNumber.MatchString(value) {
+			return invalid("is not 0 or a number without a sign or leading zeros")
+		}
+		var err error
+		if label == stream_packet_number {
+			_, err = strconv.ParseInt(value, 10, 64)
+		} else {
+			_, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil {
+			return invalid("is out of range")
+		}
+	case stream_estimated_length, stream_total_packets:
+		if !validNumber.MatchString(value) || value == "0" {
+			return invalid("is not a positive number without a sign or leading zeros")
+		}
+		i, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || (label == stream_total_packets && i > math.MaxInt64) {
+			return invalid("is out of range")
+		}
+	case stream_final_packet:
+		if strings.EqualFold(value, "eof") {
+			return "eof", nil
+		}
+		if value == "" || !validString.MatchString(value) {
+			return invalid("is not 'eof' or a reason")
+		}
+	case stream_encoding:
+		value = strings.ToLower(value)
+		switch Encoding(value) {
+		case EncodingIdentity, EncodingGzip, EncodingDeflate:
+		default:
+			return invalid("is not one of 'gzip', 'deflate' or 'identity'")
+		}
+	case stream_signature:
+		algorithm, encoded, found := strings.Cut(value, ";")
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		encoded = strings.TrimSpace(encoded)
+		if !found || (algorithm != SignatureEd25519 && algorithm != SignatureHMACSHA256) {
+			return invalid("does not start with 'ed25519;' or 'hmac-sha256;'")
+		}
+		if !validBase64.MatchString(encoded) || len(encoded)%4 != 0 {
+			return invalid("does not end with a base64 signature")
+		}
+		value = algorithm + ";" + encoded
+	case stream_name, stream_content_type:
+		if !validString.MatchString(value) {
+			return invalid("contains characters not allowed in a string")
+		}
+	case stream_mtime:
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return invalid("is not an RFC 3339 date-time")
+		}
+	default:
+		name := strings.TrimPrefix(label, stream_trailer_prefix)
+		if !validID.MatchString(name) {
+			return invalid("has a trailer name that is not an identifier")
+		}
+		if !validString.MatchString(value) {
+			return invalid("contains characters not allowed in a string")
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// The conformance cases for the grammar in protocol.md.  An empty err means
+// the header conforms.
+var grammarCases = []struct {
+	header string
+	label  string
+	value  string
+	err    string
+}{
+	// Labels and whitespace.
+	{header: "stream-id: abc", label: stream_id, value: "abc"},
+	{header: "  Stream-ID  :  abc  ", label: stream_id, value: "abc"},
+	{header: "STREAM-ID:abc", label: stream_id, value: "abc"},
+	{header: "stream-id: a_b-C9", label: stream_id, value: "a_b-C9"},
+	{header: "stream-id:", err: `stream-id: "" is not an identifier`},
+	{header: "stream-id: a b", err: `stream-id: "a b" is not an identifier`},
+	{header: "stream-id: a.b", err: `stream-id: "a.b" is not an identifier`},
+
+	// Numbers.
+	{header: "stream-packet-number: 0", label: stream_packet_number, value: "0"},
+	{header: "stream-packet-number: 42", label: stream_packet_number, value: "42"},
+	{header: "stream-packet-number: 9223372036854775807", label: stream_packet_number, value: "9223372036854775807"},
+	{header: "stream-packet-number: +1", err: `stream-packet-number: "+1" is not 0 or a number without a sign or leading zeros`},
+	{header: "stream-packet-number: -1", err: `stream-packet-number: "-1" is not 0`},
+	{header: "stream-packet-number: 01", err: `stream-packet-number: "01" is not 0`},
+	{header: "stream-packet-number: 00", err: `stream-packet-number: "00" is not 0`},
+	{header: "stream-packet-number: 1 2", err: `stream-packet-number: "1 2" is not 0`},
+	{header: "stream-packet-number: 0x1", err: `stream-packet-number: "0x1" is not 0`},
+	{header: "stream-packet-number:", err: `stream-packet-number: "" is not 0`},
+	{header: "stream-packet-number: 9223372036854775808", err: `stream-packet-number: "9223372036854775808" is out of range`},
+	{header: "stream-estimated-total-length: 100", label: stream_estimated_length, value: "100"},
+	{header: "stream-estimated-total-length: 0", err: `stream-estimated-total-length: "0" is not a positive number`},
+	{header: "stream-estimated-total-length: 18446744073709551616", err: `is out of range`},
+	{header: "stream-total-packets: 3", label: stream_total_packets, value: "3"},
+	{header: "stream-total-packets: 0", err: `stream-total-packets: "0" is not a positive number`},
+	{header: "stream-total-packets: 9223372036854775808", err: `stream-total-packets: "9223372036854775808" is out of range`},
+	{header: "stream-total-length: 0", label: stream_total_length, value: "0"},
+	{header: "stream-total-length: 007", err: `stream-total-length: "007" is not 0`},
+	{header: "stream-total-length: 18446744073709551615", label: stream_total_length, value: "18446744073709551615"},
+	{header: "stream-total-length: 18446744073709551616", err: `stream-total-length: "18446744073709551616" is out of range`},
+
+	// Final packet.
+	{header: "stream-final-packet: eof", label: stream_final_packet, value: "eof"},
+	{header: "stream-final-packet: EOF", label: stream_final_packet, value: "eof"},
+	{header: "stream-final-packet: timeout", label: stream_final_packet, value: "timeout"},
+	{header: "stream-final-packet: io-error: disk full", label: stream_final_packet, value: "io-error: disk full"},
+	{header: "stream-final-packet: Something went wrong!", label: stream_final_packet, value: "Something went wrong!"},
+	{header: "stream-final-packet:", err: `stream-final-packet: "" is not 'eof' or a reason`},
+	{header: `stream-final-packet: "quoted"`, err: `is not 'eof' or a reason`},
+
+	// Encoding.
+	{header: "stream-encoding: gzip", label: stream_encoding, value: "gzip"},
+	{header: "stream-encoding: GZip", label: stream_encoding, value: "gzip"},
+	{header: "stream-encoding: DEFLATE", label: stream_encoding, value: "deflate"},
+	{header: "stream-encoding: identity", label: stream_encoding, value: "identity"},
+	{header: "stream-encoding: br", err: `stream-encoding: "br" is not one of 'gzip', 'deflate' or 'identity'`},
+	{header: "stream-encoding: gzip+best", err: `stream-encoding: "gzip+best" is not one of`},
+
+	// Key id and signature.
+	{header: "stream-key-id: key-1", label: stream_key_id, value: "key-1"},
+	{header: "stream-key-id: key/1", err: `stream-key-id: "key/1" is not an identifier`},
+	{header: "stream-signature: ed25519;AAAA", label: stream_signature, value: "ed25519;AAAA"},
+	{header: "stream-signature: HMAC-SHA256 ; AAA=", label: stream_signature, value: "hmac-sha256;AAA="},
+	{header: "stream-signature: rsa;AAAA", err: `does not start with 'ed25519;' or 'hmac-sha256;'`},
+	{header: "stream-signature: AAAA", err: `does not start with 'ed25519;' or 'hmac-sha256;'`},
+	{header: "stream-signature: ed25519;AAA", err: `does not end with a base64 signature`},
+	{header: "stream-signature: ed25519;", err: `does not end with a base64 signature`},
+
+	// Descriptive metadata.
+	{header: "stream-name: report (1).csv", label: stream_name, value: "report (1).csv"},
+	{header: "stream-name: café", err: `stream-name: "café" contains characters not allowed in a string`},
+	{header: "stream-content-type: text/plain; charset=utf-8", label: stream_content_type, value: "text/plain; charset=utf-8"},
+	{header: `stream-content-type: text/"plain"`, err: `contains characters not allowed in a string`},
+	{header: "stream-mtime: 2025-01-02T03:04:05.5Z", label: stream_mtime, value: "2025-01-02T03:04:05.5Z"},
+	{header: "stream-mtime: 2025-01-02", err: `stream-mtime: "2025-01-02" is not an RFC 3339 date-time`},
+
+	// Trailers.
+	{header: "stream-trailer-SHA256: abc", label: "stream-trailer-sha256", value: "abc"},
+	{header: "stream-trailer-: abc", err: `has a trailer name that is not an identifier`},
+	{header: "stream-trailer-a.b: abc", err: `has a trailer name that is not an identifier`},
+	{header: "stream-trailer-count: <10>", err: `contains characters not allowed in a string`},
+}
+
+func TestValidateHeaders(t *testing.T) {
+	for _, tc := range grammarCases {
+		t.Run(tc.header, func(t *testing.T) {
+			mine, others, err := splitStrict([]string{tc.header, "other: header"})
+			assert.Equal(t, err, ValidateHeaders([]string{tc.header}))
+
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{tc.label: tc.value}, mine)
+			assert.Equal(t, []string{"other: header"}, others)
+		})
+	}
+}
+
+func TestValidateHeaders_Duplicates(t *testing.T) {
+	err := ValidateHeaders([]string{
+		"stream-id: abc",
+		"Stream-ID: abc",
+	})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorContains(t, err, "stream-id: header is present more than once")
+
+	err = ValidateHeaders([]string{
+		"stream-trailer-a: 1",
+		"stream-trailer-A: 2",
+	})
+	assert.ErrorContains(t, err, "stream-trailer-a: header is present more than once")
+
+	// Other headers may repeat.
+	assert.NoError(t, ValidateHeaders([]string{"x: 1", "x: 1", "no colon", "no colon"}))
+}
+
+func TestAssembler_StrictHeaders(t *testing.T) {
+	msg := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+		Headers: []string{
+			"stream-id: 1",
+			"stream-packet-number: +0",
+			"stream-final-packet: eof",
+		},
+		Payload: []byte("Hello"),
+	}
+
+	// The lenient parser accepts the sign.
+	var lenient Assembler
+	assert.NoError(t, lenient.ProcessWRP(context.Background(), msg))
+
+	strict := Assembler{StrictHeaders: true}
+	err := strict.ProcessWRP(context.Background(), msg)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorContains(t, err, `stream-packet-number: "+0"`)
+
+	// The values are normalized before use.
+	msg.Headers = []string{
+		"Stream-Id: 1",
+		"stream-packet-number: 0",
+		"stream-encoding: IDENTITY",
+		"stream-final-packet: EOF",
+	}
+	require.NoError(t, strict.ProcessWRP(context.Background(), msg))
+
+	got, err := io.ReadAll(&strict)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", string(got))
+}
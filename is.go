@@ -7,10 +7,12 @@ import "github.com/xmidt-org/wrp-go/v5"
 
 // Is determines if the given message is a Simple Streaming Protocol message.
 // If wrp.NoStandardValidation() is passed as a validator, then the message is
-// considered a Simple Streaming Protocol message if it has any matching headers.
+// considered a Simple Streaming Protocol message if it has any matching headers
+// or Metadata keys.
 func Is(msg wrp.Union, validators ...wrp.Processor) bool {
 	// If the message is already a SimpleStreamingMessage, then it is this type.
-	if _, ok := msg.(*simpleStreamingMessage); ok {
+	switch msg.(type) {
+	case *simpleStreamingMessage, *Packet:
 		return true
 	}
 
@@ -22,7 +24,7 @@ func Is(msg wrp.Union, validators ...wrp.Processor) bool {
 		}
 	}
 
-	mine, _ := split(tmp.Headers)
+	mine := controlFields(tmp)
 
 	// If any headers match, then this is a message of this type.
 	return len(mine) > 0
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"io"
+	"io/fs"
+)
+
+// detectLength returns the number of bytes left to read from the stream, if it
+// can be determined without reading it.  The stream is measured using Len(),
+// Stat() for regular files, or by seeking to the end and back.
+func detectLength(r io.Reader) (int64, bool) {
+	if l, ok := r.(interface{ Len() int }); ok {
+		return int64(l.Len()), true
+	}
+
+	if sizer := fileSizer(r); sizer != nil {
+		return sizer()
+	}
+
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+
+	if _, err := s.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+
+	return max(end-current, 0), true
+}
+
+// fileSizer returns a function that measures the length of the stream from the
+// current size of the file, starting at the current position, or nil if the
+// stream is not a file.  Since no reads or seeks are done, the function can be
+// used while the file is being read and written.
+func fileSizer(r io.Reader) func() (int64, bool) {
+	f, ok := r.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return nil
+	}
+
+	var start int64
+	if s, ok := r.(io.Seeker); ok {
+		var err error
+		start, err = s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil
+		}
+	}
+
+	return func() (int64, bool) {
+		info, err := f.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+
+		return max(info.Size()-start, 0), true
+	}
+}
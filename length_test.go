@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// seekOnly hides every method of the reader other than Read and Seek.
+type seekOnly struct {
+	io.ReadSeeker
+}
+
+func TestDetectLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, os.WriteFile(path, []byte("HelloWorld"), 0o600))
+
+	tests := []struct {
+		name   string
+		reader func(t *testing.T) io.Reader
+		want   int64
+		ok     bool
+	}{
+		{
+			name: "bytes reader",
+			reader: func(*testing.T) io.Reader {
+				return bytes.NewReader([]byte("HelloWorld"))
+			},
+			want: 10,
+			ok:   true,
+		}, {
+			name: "partially read strings reader",
+			reader: func(t *testing.T) io.Reader {
+				r := strings.NewReader("HelloWorld")
+				_, err := r.Read(make([]byte, 3))
+				require.NoError(t, err)
+				return r
+			},
+			want: 7,
+			ok:   true,
+		}, {
+			name: "file at an offset",
+			reader: func(t *testing.T) io.Reader {
+				f, err := os.Open(path)
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = f.Close() })
+
+				_, err = f.Seek(4, io.SeekStart)
+				require.NoError(t, err)
+				return f
+			},
+			want: 6,
+			ok:   true,
+		}, {
+			name: "seeker",
+			reader: func(t *testing.T) io.Reader {
+				r := seekOnly{strings.NewReader("HelloWorld")}
+				_, err := r.Seek(2, io.SeekStart)
+				require.NoError(t, err)
+				return r
+			},
+			want: 8,
+			ok:   true,
+		}, {
+			name: "pipe",
+			reader: func(t *testing.T) io.Reader {
+				r, w, err := os.Pipe()
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					_ = r.Close()
+					_ = w.Close()
+				})
+				return r
+			},
+		}, {
+			name: "plain reader",
+			reader: func(*testing.T) io.Reader {
+				return io.LimitReader(strings.NewReader("HelloWorld"), 5)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.reader(t)
+
+			got, ok := detectLength(r)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+
+			// The position of the stream is not changed.
+			if tt.ok {
+				rest, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Len(t, rest, int(tt.want))
+			}
+		})
+	}
+}
+
+func TestPacketizer_EstimatedLength(t *testing.T) {
+	explicit, err := New(ID("123"), Reader(strings.NewReader("HelloWorld")), EstimatedLength(100))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), explicit.estimatedSize)
+
+	detected, err := New(ID("123"), Reader(strings.NewReader("HelloWorld")), RefreshEstimatedLength(true))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), detected.estimatedSize)
+	assert.Nil(t, detected.sizer)
+
+	unknown, err := New(ID("123"), Reader(io.LimitReader(strings.NewReader("HelloWorld"), 5)))
+	require.NoError(t, err)
+	assert.Zero(t, unknown.estimatedSize)
+}
+
+func TestPacketizer_RefreshEstimatedLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh bool
+		want    []string
+	}{
+		{
+			name: "detected once",
+			want: []string{
+				"stream-estimated-total-length: 10",
+				"stream-estimated-total-length: 10",
+				"stream-estimated-total-length: 10",
+			},
+		}, {
+			name:    "refreshed as the file grows",
+			refresh: true,
+			want: []string{
+				"stream-estimated-total-length: 10",
+				"stream-estimated-total-length: 15",
+				"stream-estimated-total-length: 15",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			appendFile(t, path, "HelloWorld")
+
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+
+			packetizer, err := New(
+				ID("123"),
+				Reader(f),
+				MaxPacketSize(5),
+				RefreshEstimatedLength(tt.refresh),
+			)
+			require.NoError(t, err)
+
+			in := wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+			}
+
+			for i, want := range tt.want {
+				got, err := packetizer.Next(context.Background(), in)
+				require.NoError(t, err)
+				assert.Contains(t, got.Headers, want)
+
+				if i == 0 {
+					appendFile(t, path, "Again")
+				}
+			}
+		})
+	}
+}
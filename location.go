@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// FieldLocation is where the Packetizer places the SSP control fields of each
+// packet in the WRP message.
+type FieldLocation int
+
+const (
+	// LocationHeaders places the control fields in the Headers as
+	// "label: value" strings.  This is the default.
+	LocationHeaders FieldLocation = iota
+
+	// LocationMetadata places the control fields in the Metadata, keyed by
+	// label, for routing components that rewrite Headers but keep Metadata.
+	LocationMetadata
+
+	// LocationBoth places the control fields in both the Headers and the
+	// Metadata.
+	LocationBoth
+)
+
+func (l FieldLocation) isValid() bool {
+	return l >= LocationHeaders && l <= LocationBoth
+}
+
+// isControlLabel reports if the lowercase label is an SSP control field.
+func isControlLabel(label string) bool {
+	if _, ok := headerKeys[label]; ok {
+		return true
+	}
+	return strings.HasPrefix(label, stream_trailer_prefix)
+}
+
+// splitMetadata returns the SSP control fields in the metadata keyed by the
+// lowercase label, and the rest of the metadata.  The rest is nil if empty.
+// When strict is set, the values are checked against the grammar in
+// protocol.md.
+func splitMetadata(metadata map[string]string, strict bool) (map[string]string, map[string]string, error) {
+	var mine, others map[string]string
+
+	for key, value := range metadata {
+		label := strings.ToLower(strings.TrimSpace(key))
+		if !isControlLabel(label) {
+			if others == nil {
+				others = make(map[string]string, len(metadata))
+			}
+			others[key] = value
+			continue
+		}
+
+		if mine == nil {
+			mine = make(map[string]string)
+		}
+		if _, found := mine[label]; found && strict {
+			return nil, nil, fmt.Errorf("%w: %s: metadata key is present more than once", ErrInvalidInput, label)
+		}
+
+		value = strings.TrimSpace(value)
+		if strict {
+			var err error
+			if value, err = checkGrammar(label, value); err != nil {
+				return nil, nil, err
+			}
+		}
+		mine[label] = value
+	}
+
+	return mine, others, nil
+}
+
+// controlFields returns the SSP control fields of the message, from either
+// the Headers or the Metadata.
+func controlFields(msg *wrp.Message) map[string]string {
+	fromHeaders, _ := split(msg.Headers)
+	fromMetadata, _, _ := splitMetadata(msg.Metadata, false)
+
+	return chooseFields(fromHeaders, fromMetadata)
+}
+
+// chooseFields returns the control fields of a single location, so a packet
+// never mixes fields from both.  The metadata is used if it has any control
+// field, since intermediaries may rewrite or strip the headers but keep the
+// metadata.
+func chooseFields(headers, metadata map[string]string) map[string]string {
+	if len(metadata) > 0 {
+		return metadata
+	}
+
+	return headers
+}
+
+// place puts the control fields in the message at the location.  The msg must
+// already have the control fields removed from the Headers and Metadata.
+func (ssm *simpleStreamingMessage) place(msg *wrp.Message, location FieldLocation) {
+	ours := ssm.headers()
+
+	if location != LocationMetadata {
+		msg.Headers = append(msg.Headers, ours...)
+	}
+
+	if location == LocationHeaders {
+		return
+	}
+
+	metadata := make(map[string]string, len(msg.Metadata)+len(ours))
+	maps.Copy(metadata, msg.Metadata)
+	for _, header := range ours {
+		label, value, _ := strings.Cut(header, ": ")
+		metadata[label] = value
+	}
+	msg.Metadata = metadata
+}
@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestFieldLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		location     FieldLocation
+		wantHeaders  []string
+		wantMetadata map[string]string
+	}{
+		{
+			name:        "headers",
+			location:    LocationHeaders,
+			wantHeaders: []string{"other: value", "stream-id: 123", "stream-packet-number: 0"},
+			wantMetadata: map[string]string{
+				"app": "value",
+			},
+		}, {
+			name:        "metadata",
+			location:    LocationMetadata,
+			wantHeaders: []string{"other: value"},
+			wantMetadata: map[string]string{
+				"app":                  "value",
+				"stream-id":            "123",
+				"stream-packet-number": "0",
+			},
+		}, {
+			name:        "both",
+			location:    LocationBoth,
+			wantHeaders: []string{"other: value", "stream-id: 123", "stream-packet-number: 0"},
+			wantMetadata: map[string]string{
+				"app":                  "value",
+				"stream-id":            "123",
+				"stream-packet-number": "0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packetizer, err := New(
+				ID("123"),
+				Reader(strings.NewReader("HelloWorld")),
+				MaxPacketSize(5),
+				EstimatedLength(10),
+				WithEncoding(EncodingIdentity),
+				WithFieldLocation(tt.location),
+			)
+			require.NoError(t, err)
+
+			in := wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers:     []string{"other: value"},
+				Metadata:    map[string]string{"app": "value"},
+			}
+
+			var assembler Assembler
+			var first *wrp.Message
+			for packet, err := range packetizer.Packets(context.Background(), in) {
+				require.NoError(t, err)
+				if first == nil {
+					first = packet
+				}
+
+				assert.True(t, Is(packet))
+				id, err := GetStreamID(*packet)
+				require.NoError(t, err)
+				assert.Equal(t, "123", id)
+				length, err := GetEstimatedLength(*packet)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(10), length)
+
+				require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+			}
+
+			// Only check the fields that are in every packet.
+			want := append(tt.wantHeaders, "stream-estimated-total-length: 10")
+			if tt.location == LocationMetadata {
+				want = tt.wantHeaders
+			}
+			assert.ElementsMatch(t, want, first.Headers)
+			for key, value := range tt.wantMetadata {
+				assert.Equal(t, value, first.Metadata[key])
+			}
+			if tt.location == LocationHeaders {
+				assert.Equal(t, tt.wantMetadata, first.Metadata)
+			}
+
+			// The template is not changed.
+			assert.Equal(t, []string{"other: value"}, in.Headers)
+			assert.Equal(t, map[string]string{"app": "value"}, in.Metadata)
+
+			msg, err := assembler.AssembleMessage(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "HelloWorld", string(msg.Payload))
+			assert.Equal(t, []string{"other: value"}, msg.Headers)
+			assert.Equal(t, map[string]string{"app": "value"}, msg.Metadata)
+		})
+	}
+}
+
+func TestWithFieldLocation_Invalid(t *testing.T) {
+	_, err := New(ID("123"), Reader(strings.NewReader("")), WithFieldLocation(FieldLocation(7)))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestParse_FieldLocation(t *testing.T) {
+	msg := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+		Headers: []string{
+			"stream-id: from-headers",
+			"stream-packet-number: 1",
+			"stream-encoding: gzip",
+		},
+		Metadata: map[string]string{
+			"Stream-ID":            "from-metadata",
+			"stream-final-packet":  " eof ",
+			"stream-packet-number": "7",
+			"app":                  "value",
+		},
+	}
+
+	// The metadata is used, and none of the headers are mixed in.
+	packet, err := Parse(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "from-metadata", packet.StreamID)
+	assert.Equal(t, int64(7), packet.StreamPacketNumber)
+	assert.Equal(t, "eof", packet.StreamFinalPacket)
+	assert.Empty(t, packet.StreamEncoding)
+	assert.Nil(t, packet.Headers)
+	assert.Equal(t, map[string]string{"app": "value"}, packet.Metadata)
+
+	id, err := GetStreamID(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "from-metadata", id)
+
+	// Only the headers.
+	headersOnly := msg
+	headersOnly.Metadata = map[string]string{"app": "value"}
+	packet, err = Parse(headersOnly)
+	require.NoError(t, err)
+	assert.Equal(t, "from-headers", packet.StreamID)
+	assert.Equal(t, int64(1), packet.StreamPacketNumber)
+	assert.Equal(t, EncodingGzip, packet.StreamEncoding)
+
+	// A packet whose metadata lacks a required field is not mended from the
+	// headers.
+	partial := msg
+	partial.Metadata = map[string]string{"stream-packet-number": "7"}
+	_, err = Parse(partial)
+	assert.Error(t, err)
+
+	// The metadata is checked in strict mode.
+	msg.Headers = nil
+	msg.Metadata["stream-packet-number"] = "+7"
+	assembler := Assembler{StrictHeaders: true}
+	err = assembler.ProcessWRP(context.Background(), msg)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorContains(t, err, `stream-packet-number: "+7"`)
+}
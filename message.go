@@ -5,9 +5,13 @@ package wrpssp
 
 import (
 	"errors"
+	"maps"
+	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/xmidt-org/wrp-go/v5"
 )
@@ -21,29 +25,54 @@ const (
 	stream_estimated_length = "stream-estimated-total-length"
 	stream_final_packet     = "stream-final-packet"
 	stream_encoding         = "stream-encoding"
+	stream_key_id           = "stream-key-id"
+	stream_signature        = "stream-signature"
+	stream_total_packets    = "stream-total-packets"
+	stream_total_length     = "stream-total-length"
+	stream_trailer_prefix   = "stream-trailer-"
+	stream_name             = "stream-name"
+	stream_content_type     = "stream-content-type"
+	stream_mtime            = "stream-mtime"
 )
 
-// simpleStreamingMessage is a WRP message that contains the necessary fields
-// and methods to support streaming.  Normal interactions with this message
-// should be through the Packetizer and Assember interfaces in this package.
-type simpleStreamingMessage struct {
-	wrp.Message
-	StreamID              string
-	StreamPacketNumber    int64
-	StreamEstimatedLength uint64
-	StreamFinalPacket     string
-	StreamEncoding        Encoding
-}
+// simpleStreamingMessage is a Packet with the methods used by the Packetizer
+// and Assembler.  Normal interactions with this message should be through the
+// Packetizer and Assember interfaces in this package, or the Packet type.
+type simpleStreamingMessage Packet
 
 var _ wrp.Union = &simpleStreamingMessage{}
 
 func (ssm *simpleStreamingMessage) From(msg *wrp.Message, validators ...wrp.Processor) error {
+	return ssm.parse(msg, false, validators...)
+}
+
+// parse is From, but when strict is set the headers are checked against the
+// grammar in protocol.md by splitStrict.
+func (ssm *simpleStreamingMessage) parse(msg *wrp.Message, strict bool, validators ...wrp.Processor) error {
 	err := ssm.Message.From(msg, wrp.NoStandardValidation())
 	if err != nil {
 		return err
 	}
 
-	mine, others := split(msg.Headers)
+	var mine map[string]string
+	var others []string
+	if strict {
+		mine, others, err = splitStrict(msg.Headers)
+		if err != nil {
+			return err
+		}
+	} else {
+		mine, others = split(msg.Headers)
+	}
+
+	fromMetadata, otherMetadata, err := splitMetadata(msg.Metadata, strict)
+	if err != nil {
+		return err
+	}
+	if len(fromMetadata) > 0 {
+		ssm.Metadata = otherMetadata
+	}
+	mine = chooseFields(mine, fromMetadata)
 	if len(others) == 0 {
 		ssm.Headers = nil
 	} else {
@@ -61,6 +90,12 @@ func (ssm *simpleStreamingMessage) MsgType() wrp.MessageType {
 }
 
 func (ssm *simpleStreamingMessage) To(msg *wrp.Message, validators ...wrp.Processor) error {
+	return ssm.to(msg, LocationHeaders, validators...)
+}
+
+// to is To, but the control fields are placed at the location.  Any control
+// fields already in the Headers or Metadata are replaced.
+func (ssm *simpleStreamingMessage) to(msg *wrp.Message, location FieldLocation, validators ...wrp.Processor) error {
 	if err := ssm.Validate(validators...); err != nil {
 		return err
 	}
@@ -68,9 +103,16 @@ func (ssm *simpleStreamingMessage) To(msg *wrp.Message, validators ...wrp.Proces
 	_ = ssm.Message.To(msg, wrp.NoStandardValidation())
 
 	_, others := split(ssm.Headers)
-	ours := ssm.headers()
+	if len(others) == 0 && location == LocationMetadata {
+		others = nil
+	}
+	msg.Headers = others
+
+	if fields, rest, _ := splitMetadata(ssm.Metadata, false); len(fields) > 0 {
+		msg.Metadata = rest
+	}
 
-	msg.Headers = append(others, ours...)
+	ssm.place(msg, location)
 
 	return nil
 }
@@ -84,7 +126,7 @@ func (ssm *simpleStreamingMessage) Validate(validators ...wrp.Processor) error {
 		return nil
 	}
 
-	errs := make([]error, 0, 3)
+	errs := make([]error, 0, 4)
 
 	if ssm.StreamID == "" {
 		errs = append(errs, errors.New("StreamID is required"))
@@ -97,6 +139,21 @@ func (ssm *simpleStreamingMessage) Validate(validators ...wrp.Processor) error {
 	if !ssm.StreamEncoding.isValid() {
 		errs = append(errs, errors.New("StreamEncoding must be one of identity, gzip, or deflate"))
 	}
+	if ssm.StreamKeyID != "" && !validID.MatchString(ssm.StreamKeyID) {
+		errs = append(errs, errors.New("StreamKeyID contains invalid characters"))
+	}
+	if ssm.StreamTotalPackets < 0 {
+		errs = append(errs, errors.New("StreamTotalPackets must be non-negative"))
+	}
+	if err := validateTrailers(ssm.StreamTrailers); err != nil {
+		errs = append(errs, err)
+	}
+	if !validString.MatchString(ssm.StreamName) {
+		errs = append(errs, errors.New("StreamName contains invalid characters"))
+	}
+	if !validString.MatchString(ssm.StreamContentType) {
+		errs = append(errs, errors.New("StreamContentType contains invalid characters"))
+	}
 
 	if len(errs) == 0 {
 		return nil
@@ -123,6 +180,14 @@ func (ssm *simpleStreamingMessage) from(headers map[string]string) error {
 	ssm.StreamEstimatedLength = 0
 	ssm.StreamFinalPacket = ""
 	ssm.StreamEncoding = ""
+	ssm.StreamKeyID = ""
+	ssm.StreamSignature = ""
+	ssm.StreamTotalPackets = 0
+	ssm.StreamTotalLength = 0
+	ssm.StreamTrailers = nil
+	ssm.StreamName = ""
+	ssm.StreamContentType = ""
+	ssm.StreamModTime = time.Time{}
 	for key, value := range headers {
 		switch key {
 		case stream_id:
@@ -143,7 +208,7 @@ func (ssm *simpleStreamingMessage) from(headers map[string]string) error {
 			}
 			ssm.StreamPacketNumber = i
 		case stream_estimated_length:
-			i, err := parseEstimatedLength(value)
+			i, err := parseUint(value)
 			if err != nil {
 				return err
 			}
@@ -157,6 +222,45 @@ func (ssm *simpleStreamingMessage) from(headers map[string]string) error {
 			}
 		case stream_encoding:
 			ssm.StreamEncoding = Encoding(value)
+		case stream_key_id:
+			ssm.StreamKeyID = value
+		case stream_signature:
+			ssm.StreamSignature = value
+		case stream_total_packets:
+			i, err := parseUint(value)
+			if err != nil {
+				return err
+			}
+			if i > math.MaxInt64 {
+				return errors.Join(ErrInvalidInput, strconv.ErrRange)
+			}
+			ssm.StreamTotalPackets = int64(i)
+		case stream_total_length:
+			i, err := parseUint(value)
+			if err != nil {
+				return err
+			}
+			ssm.StreamTotalLength = i
+		case stream_name:
+			ssm.StreamName = value
+		case stream_content_type:
+			ssm.StreamContentType = value
+		case stream_mtime:
+			if value == "" {
+				break
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return errors.Join(ErrInvalidInput, err)
+			}
+			ssm.StreamModTime = t
+		default:
+			if name, found := strings.CutPrefix(key, stream_trailer_prefix); found {
+				if ssm.StreamTrailers == nil {
+					ssm.StreamTrailers = make(map[string]string)
+				}
+				ssm.StreamTrailers[name] = value
+			}
 		}
 	}
 
@@ -164,7 +268,7 @@ func (ssm *simpleStreamingMessage) from(headers map[string]string) error {
 }
 
 func (ssm *simpleStreamingMessage) headers() []string {
-	headers := make([]string, 0, 5)
+	headers := make([]string, 0, 9)
 
 	if ssm.StreamID != "" {
 		headers = append(headers, stream_id+": "+ssm.StreamID)
@@ -179,8 +283,8 @@ func (ssm *simpleStreamingMessage) headers() []string {
 	}
 
 	if ssm.StreamFinalPacket != "" {
-		final := ssm.StreamFinalPacket
-		if strings.ToLower(strings.TrimSpace(final)) == "eof" {
+		final := strings.TrimSpace(ssm.StreamFinalPacket)
+		if strings.ToLower(final) == "eof" {
 			final = "eof"
 		}
 		headers = append(headers, stream_final_packet+": "+final)
@@ -190,10 +294,43 @@ func (ssm *simpleStreamingMessage) headers() []string {
 		headers = append(headers, stream_encoding+": "+ssm.StreamEncoding.string())
 	}
 
+	if ssm.StreamKeyID != "" {
+		headers = append(headers, stream_key_id+": "+ssm.StreamKeyID)
+	}
+
+	if ssm.StreamFinalPacket != "" && ssm.StreamTotalPackets > 0 {
+		headers = append(headers,
+			stream_total_packets+": "+strconv.FormatInt(ssm.StreamTotalPackets, 10),
+			stream_total_length+": "+strconv.FormatUint(ssm.StreamTotalLength, 10),
+		)
+	}
+
+	if ssm.StreamName != "" {
+		headers = append(headers, stream_name+": "+ssm.StreamName)
+	}
+
+	if ssm.StreamContentType != "" {
+		headers = append(headers, stream_content_type+": "+ssm.StreamContentType)
+	}
+
+	if !ssm.StreamModTime.IsZero() {
+		headers = append(headers, stream_mtime+": "+ssm.StreamModTime.UTC().Format(time.RFC3339Nano))
+	}
+
+	if ssm.StreamFinalPacket != "" {
+		for _, name := range slices.Sorted(maps.Keys(ssm.StreamTrailers)) {
+			headers = append(headers, stream_trailer_prefix+name+": "+ssm.StreamTrailers[name])
+		}
+	}
+
+	if ssm.StreamSignature != "" {
+		headers = append(headers, stream_signature+": "+ssm.StreamSignature)
+	}
+
 	return headers
 }
 
-func parseEstimatedLength(s string) (uint64, error) {
+func parseUint(s string) (uint64, error) {
 	zero, s := isZero(s)
 	if zero || s == "" {
 		return 0, nil
@@ -228,6 +365,13 @@ var headerKeys = map[string]struct{}{
 	stream_estimated_length: {},
 	stream_final_packet:     {},
 	stream_encoding:         {},
+	stream_key_id:           {},
+	stream_signature:        {},
+	stream_total_packets:    {},
+	stream_total_length:     {},
+	stream_name:             {},
+	stream_content_type:     {},
+	stream_mtime:            {},
 }
 
 func split(headers []string) (map[string]string, []string) {
@@ -244,7 +388,7 @@ func split(headers []string) (map[string]string, []string) {
 		key = strings.TrimSpace(key)
 		key = strings.ToLower(key)
 
-		if _, ok := headerKeys[key]; !ok {
+		if !isControlLabel(key) {
 			others = append(others, header)
 			continue
 		}
@@ -265,13 +409,13 @@ func GetEstimatedLength(msg wrp.Message) (uint64, error) {
 		return 0, wrp.ErrNotHandled
 	}
 
-	mine, _ := split(msg.Headers)
+	mine := controlFields(&msg)
 	val, ok := mine[stream_estimated_length]
 	if !ok {
 		return 0, ErrNotAvailable
 	}
 
-	return parseEstimatedLength(val)
+	return parseUint(val)
 }
 
 // GetStreamID returns the stream ID of the message if it is an SSP message.
@@ -283,7 +427,7 @@ func GetStreamID(msg wrp.Message) (string, error) {
 		return "", wrp.ErrNotHandled
 	}
 
-	mine, _ := split(msg.Headers)
+	mine := controlFields(&msg)
 	if id, ok := mine[stream_id]; ok {
 		return id, nil
 	}
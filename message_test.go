@@ -220,6 +220,39 @@ func TestSimpleStreamingMessage_InnerFrom(t *testing.T) {
 			},
 			want: simpleStreamingMessage{},
 			err:  ErrInvalidInput,
+		}, {
+			name: "Stream summary",
+			headers: map[string]string{
+				stream_final_packet:  "eof",
+				stream_total_packets: "3",
+				stream_total_length:  "0",
+			},
+			want: simpleStreamingMessage{
+				StreamPacketNumber: -1,
+				StreamFinalPacket:  "eof",
+				StreamTotalPackets: 3,
+			},
+		}, {
+			name: "Invalid StreamTotalPackets, too large",
+			headers: map[string]string{
+				stream_total_packets: "9223372036854775808",
+			},
+			want: simpleStreamingMessage{},
+			err:  ErrInvalidInput,
+		}, {
+			name: "Invalid StreamModTime",
+			headers: map[string]string{
+				stream_mtime: "yesterday",
+			},
+			want: simpleStreamingMessage{},
+			err:  ErrInvalidInput,
+		}, {
+			name: "Invalid StreamTotalLength, negative",
+			headers: map[string]string{
+				stream_total_length: "-1",
+			},
+			want: simpleStreamingMessage{},
+			err:  ErrInvalidInput,
 		},
 	}
 
@@ -368,6 +401,19 @@ func TestSimpleStreamingMessage_Validate(t *testing.T) {
 				StreamEncoding:        EncodingGzip,
 			},
 			wantErr: ErrInvalidInput,
+		}, {
+			name: "Invalid StreamKeyID characters",
+			ssm: simpleStreamingMessage{
+				Message: wrp.Message{
+					Type:        wrp.SimpleEventMessageType,
+					Source:      "self:/service",
+					Destination: "event:foo",
+				},
+				StreamID:           "test-stream-id",
+				StreamPacketNumber: 1,
+				StreamKeyID:        "key id",
+			},
+			wantErr: ErrInvalidInput,
 		}, {
 			name: "Invalid Message - missing Destination",
 			ssm: simpleStreamingMessage{
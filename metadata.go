@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StreamMetadata describes the content of a stream, such as the file being
+// sent.  Every field is optional.
+type StreamMetadata struct {
+	// Name is the value of the stream-name header.
+	Name string
+
+	// ContentType is the value of the stream-content-type header.
+	ContentType string
+
+	// ModTime is the value of the stream-mtime header.
+	ModTime time.Time
+}
+
+// isZero reports if none of the fields are set.
+func (m StreamMetadata) isZero() bool {
+	return m.Name == "" && m.ContentType == "" && m.ModTime.IsZero()
+}
+
+// withFile returns the metadata with the fields that are not set filled in
+// from the file, if it is a regular file.  Values that are not valid header
+// values are left empty.
+func (m StreamMetadata) withFile(f *os.File) StreamMetadata {
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return m
+	}
+
+	name := filepath.Base(f.Name())
+	if m.Name == "" && validString.MatchString(name) {
+		m.Name = name
+	}
+
+	if ct := mime.TypeByExtension(filepath.Ext(name)); m.ContentType == "" && validString.MatchString(ct) {
+		m.ContentType = ct
+	}
+
+	if m.ModTime.IsZero() {
+		m.ModTime = info.ModTime()
+	}
+
+	return m
+}
+
+// StreamMetadata returns the metadata of the stream from the first packet
+// received that has any.  The metadata is available before the stream has been
+// read.  Until a packet with metadata has been received, ErrNotAvailable is
+// returned.
+func (a *Assembler) StreamMetadata() (StreamMetadata, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if a.metadata == nil {
+		return StreamMetadata{}, ErrNotAvailable
+	}
+
+	return *a.metadata, nil
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestStreamMetadata(t *testing.T) {
+	mtime := time.Date(2025, 6, 1, 12, 30, 0, 500, time.FixedZone("EST", -5*60*60))
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, os.WriteFile(path, []byte("HelloWorld"), 0o600))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+
+	tests := []struct {
+		name    string
+		opts    func(t *testing.T) []Option
+		headers []string
+		want    StreamMetadata
+		err     error
+	}{
+		{
+			name: "options",
+			opts: func(*testing.T) []Option {
+				return []Option{
+					Reader(strings.NewReader("HelloWorld")),
+					Name("report (1).json"),
+					ContentType("application/json; charset=utf-8"),
+					ModTime(mtime),
+				}
+			},
+			headers: []string{
+				"stream-name: report (1).json",
+				"stream-content-type: application/json; charset=utf-8",
+				"stream-mtime: 2025-06-01T17:30:00.0000005Z",
+			},
+			want: StreamMetadata{
+				Name:        "report (1).json",
+				ContentType: "application/json; charset=utf-8",
+				ModTime:     mtime.UTC(),
+			},
+		}, {
+			name: "from a file",
+			opts: func(t *testing.T) []Option {
+				f, err := os.Open(path)
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = f.Close() })
+
+				return []Option{Reader(f)}
+			},
+			headers: []string{
+				"stream-name: report.json",
+				"stream-content-type: application/json",
+				"stream-mtime: 2025-06-01T17:30:00.0000005Z",
+			},
+			want: StreamMetadata{
+				Name:        "report.json",
+				ContentType: "application/json",
+				ModTime:     mtime.UTC(),
+			},
+		}, {
+			name: "options take precedence over the file",
+			opts: func(t *testing.T) []Option {
+				f, err := os.Open(path)
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = f.Close() })
+
+				return []Option{Name("upload"), Reader(f), ContentType("text/plain")}
+			},
+			headers: []string{
+				"stream-name: upload",
+				"stream-content-type: text/plain",
+				"stream-mtime: 2025-06-01T17:30:00.0000005Z",
+			},
+			want: StreamMetadata{
+				Name:        "upload",
+				ContentType: "text/plain",
+				ModTime:     mtime.UTC(),
+			},
+		}, {
+			name: "invalid name",
+			opts: func(*testing.T) []Option {
+				return []Option{Reader(strings.NewReader("HelloWorld")), Name("résumé.pdf")}
+			},
+			err: ErrInvalidInput,
+		}, {
+			name: "invalid content type",
+			opts: func(*testing.T) []Option {
+				return []Option{Reader(strings.NewReader("HelloWorld")), ContentType("text/plain\n")}
+			},
+			err: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{ID("123"), MaxPacketSize(5)}, tt.opts(t)...)
+			packetizer, err := New(opts...)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, packetizer)
+				return
+			}
+			require.NoError(t, err)
+
+			var assembler Assembler
+			_, err = assembler.StreamMetadata()
+			assert.ErrorIs(t, err, ErrNotAvailable)
+
+			in := wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+			}
+			for packet, err := range packetizer.Packets(context.Background(), in) {
+				require.NoError(t, err)
+				for _, header := range tt.headers {
+					assert.Contains(t, packet.Headers, header)
+				}
+				require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+			}
+
+			got, err := assembler.StreamMetadata()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStreamMetadata_NotAFile(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	require.NoError(t, w.Close())
+
+	packetizer, err := New(ID("123"), Reader(r))
+	require.NoError(t, err)
+	assert.Equal(t, StreamMetadata{}, packetizer.metadata)
+}
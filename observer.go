@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+)
+
+// EventKind identifies what happened in an Event.
+type EventKind string
+
+const (
+	// EventPacketEmitted is sent by the Packetizer for each packet produced.
+	EventPacketEmitted EventKind = "packet-emitted"
+
+	// EventEncodingFallback is sent by the Packetizer when encoding a packet
+	// fails and identity encoding is used for the rest of the stream.
+	EventEncodingFallback EventKind = "encoding-fallback"
+
+	// EventDuplicateDropped is sent by the Assembler when a packet that is
+	// already buffered is received again.
+	EventDuplicateDropped EventKind = "duplicate-dropped"
+
+	// EventLatePacketDropped is sent by the Assembler when a packet that has
+	// already been read is received again.
+	EventLatePacketDropped EventKind = "late-packet-dropped"
+
+	// EventConflictingDuplicate is sent by the Assembler when a packet is
+	// rejected because it differs from the packet with the same number that
+	// was already received.  It is only sent when StrictDuplicates is set.
+	EventConflictingDuplicate EventKind = "conflicting-duplicate"
+
+	// EventGapExceeded is sent by the Assembler when a packet is rejected
+	// because it is too far ahead of the current packet.
+	EventGapExceeded EventKind = "gap-exceeded"
+
+	// EventDecodeFailed is sent by the Assembler when a packet cannot be
+	// decrypted or decoded.
+	EventDecodeFailed EventKind = "decode-failed"
+
+	// EventStreamCompleted is sent when the stream ends with io.EOF: by the
+	// Packetizer when the final packet is produced, and by the Assembler when
+	// the end of the stream is read.
+	EventStreamCompleted EventKind = "stream-completed"
+
+	// EventStreamAborted is sent when the stream ends with any other error.
+	EventStreamAborted EventKind = "stream-aborted"
+)
+
+// Event describes something that happened to a stream.  The fields that do not
+// apply to the Kind are left empty.
+type Event struct {
+	Kind         EventKind
+	StreamID     string
+	PacketNumber int64
+
+	// Size is the payload size of the packet, as sent.
+	Size int
+
+	// Encoding is the encoding of the packet, or the encoding that failed for
+	// EventEncodingFallback.
+	Encoding Encoding
+
+	// Err is the error that caused the event, if any.
+	Err error
+}
+
+// Observer receives events from a Packetizer or Assembler, for metrics and
+// logging.  Observe must be safe for concurrent use and should return quickly
+// since it is called from Next, Read and ProcessWRP.
+type Observer interface {
+	Observe(Event)
+}
+
+// observe sends the event to the observer, if there is one.
+func (p *Packetizer) observe(e Event) {
+	if p.observer != nil {
+		p.observer.Observe(e)
+	}
+}
+
+// observe queues the event for the Observer so it is sent once the lock is
+// released.  Must be called with the lock held.
+func (a *Assembler) observe(e Event) {
+	if a.Observer != nil {
+		a.events = append(a.events, e)
+	}
+}
+
+// takeEvents returns the queued events.  Must be called with the lock held.
+func (a *Assembler) takeEvents() []Event {
+	events := a.events
+	a.events = nil
+	return events
+}
+
+// dispatch sends the events to the Observer.  Must be called without the lock
+// held.
+func (a *Assembler) dispatch(events []Event) {
+	for _, e := range events {
+		a.Observer.Observe(e)
+	}
+}
+
+// streamEndEvent returns the event for the stream ending with err.
+func streamEndEvent(id string, number int64, err error) Event {
+	if err == nil {
+		return Event{Kind: EventStreamCompleted, StreamID: id, PacketNumber: number}
+	}
+	return Event{Kind: EventStreamAborted, StreamID: id, PacketNumber: number, Err: err}
+}
+
+// SlogObserver returns an Observer that logs each event to the logger.  Events
+// for individual packets are logged at the debug level, stream completion at
+// the info level, and problems at the warn or error level.
+func SlogObserver(logger *slog.Logger) Observer {
+	return &slogObserver{logger: logger}
+}
+
+type slogObserver struct {
+	logger *slog.Logger
+}
+
+func (o *slogObserver) Observe(e Event) {
+	level := slog.LevelDebug
+	switch e.Kind {
+	case EventStreamCompleted:
+		level = slog.LevelInfo
+	case EventEncodingFallback, EventGapExceeded, EventStreamAborted:
+		level = slog.LevelWarn
+	case EventDecodeFailed, EventConflictingDuplicate:
+		level = slog.LevelError
+	}
+
+	attrs := []slog.Attr{
+		slog.String("stream_id", e.StreamID),
+		slog.Int64("packet_number", e.PacketNumber),
+	}
+	if e.Size > 0 {
+		attrs = append(attrs, slog.Int("size", e.Size))
+	}
+	if e.Encoding != "" {
+		attrs = append(attrs, slog.String("encoding", string(e.Encoding)))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+
+	o.logger.LogAttrs(context.Background(), level, "wrpssp: "+string(e.Kind), attrs...)
+}
+
+// ExpvarObserver returns an Observer that counts the events in the map, keyed
+// by the EventKind.  The payload bytes of the packets emitted are counted in
+// the "packet-emitted-bytes" key.
+func ExpvarObserver(m *expvar.Map) Observer {
+	return &expvarObserver{m: m}
+}
+
+type expvarObserver struct {
+	m *expvar.Map
+}
+
+func (o *expvarObserver) Observe(e Event) {
+	o.m.Add(string(e.Kind), 1)
+	if e.Kind == EventPacketEmitted {
+		o.m.Add(string(EventPacketEmitted)+"-bytes", int64(e.Size))
+	}
+}
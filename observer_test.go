@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// recorder is an Observer that records the events.
+type recorder struct {
+	m      sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Observe(e Event) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) kinds() []EventKind {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	kinds := make([]EventKind, 0, len(r.events))
+	for _, e := range r.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func TestPacketizer_Observer(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		var r recorder
+		packetizer, err := New(
+			ID("123"),
+			Reader(strings.NewReader("HelloWorld")),
+			MaxPacketSize(5),
+			WithEncoding(EncodingIdentity),
+			WithObserver(&r),
+		)
+		require.NoError(t, err)
+
+		for _, err := range packetizer.Packets(context.Background(), in) {
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []EventKind{
+			EventPacketEmitted,
+			EventPacketEmitted,
+			EventPacketEmitted,
+			EventStreamCompleted,
+		}, r.kinds())
+		assert.Equal(t, Event{
+			Kind:         EventPacketEmitted,
+			StreamID:     "123",
+			PacketNumber: 1,
+			Size:         5,
+			Encoding:     EncodingIdentity,
+		}, r.events[1])
+		assert.Equal(t, Event{
+			Kind:         EventStreamCompleted,
+			StreamID:     "123",
+			PacketNumber: 2,
+		}, r.events[3])
+	})
+
+	t.Run("encoding fallback and abort", func(t *testing.T) {
+		var r recorder
+		packetizer, err := New(
+			ID("123"),
+			Reader(strings.NewReader("HelloWorld")),
+			MaxPacketSize(5),
+			WithObserver(&r),
+		)
+		require.NoError(t, err)
+		packetizer.encoding = "invalid"
+
+		_, err = packetizer.Next(context.Background(), in)
+		require.NoError(t, err)
+		_, err = packetizer.Abort(context.Background(), in, "done")
+		require.ErrorIs(t, err, ErrAborted)
+
+		assert.Equal(t, []EventKind{
+			EventEncodingFallback,
+			EventPacketEmitted,
+			EventPacketEmitted,
+			EventStreamAborted,
+		}, r.kinds())
+		assert.Equal(t, Encoding("invalid"), r.events[0].Encoding)
+		assert.Error(t, r.events[0].Err)
+		assert.ErrorIs(t, r.events[3].Err, ErrAborted)
+	})
+}
+
+func TestAssembler_Observer(t *testing.T) {
+	packet := func(n string, payload string, headers ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers:     append([]string{"stream-id: 1", "stream-packet-number: " + n}, headers...),
+			Payload:     []byte(payload),
+		}
+	}
+
+	t.Run("dropped packets and completion", func(t *testing.T) {
+		var r recorder
+		assembler := Assembler{
+			MaxPacketGap: 2,
+			Observer:     &r,
+		}
+
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+		assert.ErrorIs(t, assembler.ProcessWRP(context.Background(), packet("5", "Later")), ErrPacketGapExceeded)
+
+		buf := make([]byte, 5)
+		_, err := io.ReadFull(&assembler, buf)
+		require.NoError(t, err)
+
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello")))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("1", "World", "stream-final-packet: eof")))
+
+		got, err := io.ReadAll(&assembler)
+		assert.NoError(t, err)
+		assert.Equal(t, "World", string(got))
+
+		assert.Equal(t, []EventKind{
+			EventDuplicateDropped,
+			EventGapExceeded,
+			EventLatePacketDropped,
+			EventStreamCompleted,
+		}, r.kinds())
+		assert.Equal(t, int64(5), r.events[1].PacketNumber)
+		assert.ErrorIs(t, r.events[1].Err, ErrPacketGapExceeded)
+		assert.Equal(t, Event{
+			Kind:         EventStreamCompleted,
+			StreamID:     "1",
+			PacketNumber: 1,
+		}, r.events[3])
+	})
+
+	t.Run("decode failure", func(t *testing.T) {
+		var r recorder
+		assembler := Assembler{Observer: &r}
+
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "not gzip", "stream-encoding: gzip")))
+
+		_, err := io.ReadAll(&assembler)
+		assert.Error(t, err)
+
+		assert.Equal(t, []EventKind{EventDecodeFailed, EventStreamAborted}, r.kinds())
+	})
+
+	t.Run("aborted", func(t *testing.T) {
+		var r recorder
+		assembler := Assembler{Observer: &r}
+
+		require.NoError(t, assembler.ProcessWRP(context.Background(), packet("0", "Hello", "stream-final-packet: timeout")))
+
+		_, err := io.ReadAll(&assembler)
+		assert.ErrorIs(t, err, ErrStreamTimeout)
+
+		// Reading again does not repeat the event.
+		_, err = assembler.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, ErrStreamTimeout)
+
+		assert.Equal(t, []EventKind{EventStreamAborted}, r.kinds())
+		assert.ErrorIs(t, r.events[0].Err, ErrStreamTimeout)
+	})
+}
+
+func TestSlogObserver(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	o := SlogObserver(logger)
+
+	o.Observe(Event{Kind: EventPacketEmitted, StreamID: "123", PacketNumber: 1, Size: 5, Encoding: EncodingGzip})
+	o.Observe(Event{Kind: EventStreamAborted, StreamID: "123", PacketNumber: 2, Err: errors.New("boom")})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `level=DEBUG msg="wrpssp: packet-emitted" stream_id=123 packet_number=1 size=5 encoding=gzip`)
+	assert.Contains(t, lines[1], `level=WARN msg="wrpssp: stream-aborted" stream_id=123 packet_number=2 error=boom`)
+}
+
+func TestExpvarObserver(t *testing.T) {
+	m := new(expvar.Map).Init()
+	o := ExpvarObserver(m)
+
+	o.Observe(Event{Kind: EventPacketEmitted, Size: 5})
+	o.Observe(Event{Kind: EventPacketEmitted, Size: 3})
+	o.Observe(Event{Kind: EventDuplicateDropped})
+
+	assert.Equal(t, "2", m.Get("packet-emitted").String())
+	assert.Equal(t, "8", m.Get("packet-emitted-bytes").String())
+	assert.Equal(t, "1", m.Get("duplicate-dropped").String())
+}
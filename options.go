@@ -6,6 +6,8 @@ package wrpssp
 import (
 	"fmt"
 	"io"
+	"os"
+	"time"
 )
 
 // Option is a functional option for the Stream.
@@ -29,7 +31,10 @@ func ID(id string) Option {
 }
 
 // EstimatedLength sets the estimated length of the stream.  This is optional.
-// If the size is less than 1, the default value of 0 is used.
+// If the size is less than 1, the length is detected from the stream if it has
+// a Len() method (*bytes.Reader, *strings.Reader), is a regular file with a
+// Stat() method (*os.File), or is an io.Seeker.  Otherwise no estimated length
+// is sent.
 //
 // This field is used to help the receiver determine the progress of the stream
 // if it is a fixed length.
@@ -43,7 +48,24 @@ func EstimatedLength(size int64) Option {
 	})
 }
 
+// RefreshEstimatedLength enables updating the estimated length detected from a
+// regular file before each packet, for files that grow while they are being
+// sent.  This is optional.  It has no effect if the estimated length is set
+// with EstimatedLength or the stream is not a file.
+func RefreshEstimatedLength(enabled bool) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.refreshLength = enabled
+		return nil
+	})
+}
+
 // Reader sets the stream to read from.  This is a required field.
+//
+// If the stream is an *os.File for a regular file, the name, content type and
+// modification time of the stream are set from the file unless they are set
+// by the Name, ContentType or ModTime options.  The content type is guessed
+// from the file extension.  Values that do not follow the <string> grammar in
+// protocol.md are not sent.
 func Reader(r io.Reader) Option {
 	return optionFunc(func(s *Packetizer) error {
 		s.stream = r
@@ -51,6 +73,35 @@ func Reader(r io.Reader) Option {
 	})
 }
 
+// Name sets the name of the stream sent in the stream-name header, such as
+// the name of the file being sent.  This is optional.  The name must follow
+// the <string> grammar in protocol.md.
+func Name(name string) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.metadata.Name = name
+		return nil
+	})
+}
+
+// ContentType sets the media type of the stream sent in the
+// stream-content-type header.  This is optional.  The content type must
+// follow the <string> grammar in protocol.md.
+func ContentType(contentType string) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.metadata.ContentType = contentType
+		return nil
+	})
+}
+
+// ModTime sets the modification time of the stream sent in the stream-mtime
+// header.  This is optional.
+func ModTime(t time.Time) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.metadata.ModTime = t
+		return nil
+	})
+}
+
 // MaxPacketSize sets the maximum size of a packet.  This is optional.  If the
 // size is less than 1, the default value of 64KB is used.
 func MaxPacketSize(size int) Option {
@@ -63,6 +114,57 @@ func MaxPacketSize(size int) Option {
 	})
 }
 
+// maxPayloadSize limits the size of each packet payload as sent, after it is
+// encoded and encrypted.  Room is reserved for the encryption overhead, and a
+// packet whose payload does not shrink when encoded is sent unencoded.
+func maxPayloadSize(size int) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.maxPayloadSize = size
+		return nil
+	})
+}
+
+// MaxLatency sets the longest time data read from the stream may wait before
+// it is sent.  This is optional.  If the latency is less than 1, the default
+// behavior of waiting until a packet is full or the stream ends is used.
+//
+// This is useful for live sources (log tails, sensor feeds) that produce data
+// slowly.  The latency budget starts when the first byte of a packet is read,
+// and Next returns the partial packet once the budget expires.  When set, the
+// stream is read from a background goroutine so Next is never blocked by a
+// Read that is in progress, and no bytes are lost.  The goroutine only runs
+// while Next is waiting for data.  A Read in progress when Next returns is
+// interrupted if the stream supports read deadlines; otherwise the goroutine
+// exits once it returns, and the bytes read are kept for the next packet.
+func MaxLatency(d time.Duration) Option {
+	return optionFunc(func(s *Packetizer) error {
+		if d < 1 {
+			d = 0
+		}
+		s.maxLatency = d
+		return nil
+	})
+}
+
+// InterruptibleReads enables interrupting a Read of the stream that is blocked
+// when the context passed to Next is canceled.  This is optional.
+//
+// Streams that support read deadlines (net.Conn, or an *os.File for a pipe or
+// device) are always interrupted by setting a deadline in the past.  When
+// enabled, reads from all other streams, including those whose
+// SetReadDeadline returns an error such as os.ErrNoDeadline, are run in a
+// background goroutine so Next can return promptly.  The goroutine exits once
+// Next returns, after the Read in progress returns.
+//
+// In both cases the final packet produced carries the cancellation as the
+// reason the stream ended.
+func InterruptibleReads(enabled bool) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.interruptible = enabled
+		return nil
+	})
+}
+
 // WithEncoding sets the encoding of the stream.  This is optional.  If the encoding
 // is not set, the default value of EncodingGzip is used.
 func WithEncoding(e Encoding) Option {
@@ -72,6 +174,113 @@ func WithEncoding(e Encoding) Option {
 	})
 }
 
+// WithEncryption enables encrypting each packet payload with AES-GCM using the
+// key with the id provided by keys.  This is optional.  The key id is sent in
+// the stream-key-id header and must be a non-empty string containing only
+// [A-Za-z0-9_-].  The keys must not be nil, and must provide a valid key for
+// the id.
+//
+// The payload is encrypted after it is encoded, and the stream ID and packet
+// number are bound to it as associated data so packets cannot be moved
+// between streams or reordered without detection.
+func WithEncryption(keyID string, keys KeyProvider) Option {
+	return optionFunc(func(s *Packetizer) error {
+		if keys == nil {
+			return fmt.Errorf("%w: keys must not be nil", ErrInvalidInput)
+		}
+
+		if !validID.MatchString(keyID) {
+			return fmt.Errorf("%w: key id is empty or contains invalid characters", ErrInvalidInput)
+		}
+
+		s.keyID = keyID
+		s.keys = keys
+		return nil
+	})
+}
+
+// WithSigner sets the Signer used to sign each packet.  This is optional.  The
+// signature is sent in the stream-signature header and covers the SSP headers,
+// including the packet number, and the payload after any encoding and
+// encryption is applied.
+//
+// If the Signer returns an error, the Packetizer stops processing and returns
+// the error instead of the packet.
+func WithSigner(signer Signer) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.signer = signer
+		return nil
+	})
+}
+
+// WithFinalReason sets the function that maps the error that ended the stream
+// early to the Reason sent in the stream-final-packet header.  This is
+// optional.  If not set, DefaultFinalReason is used.
+//
+// The message of the Reason is sanitized before it is sent, but it should not
+// include details the receiver should not see.
+func WithFinalReason(fn func(error) Reason) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.reason = fn
+		return nil
+	})
+}
+
+// WithTrailers sets the function that provides the trailers sent with the
+// final packet of the stream, similar to HTTP trailers.  This is optional.  The
+// function is called once the stream has been read to the end, so it may
+// include details only known at that point, such as record counts or hashes.
+//
+// Each trailer is sent as a stream-trailer-<name> header.  Names must only
+// contain [A-Za-z0-9_-] and are sent lowercase.  Values must follow the
+// <string> grammar in protocol.md.  Trailers are not encrypted.
+//
+// If the function returns an error, or the trailers are invalid, the stream
+// ends with that error instead of io.EOF and no trailers are sent.
+func WithTrailers(fn func() (map[string]string, error)) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.trailers = fn
+		return nil
+	})
+}
+
+// WithProgress sets the function called with the progress of the stream as
+// packets are produced.  This is optional.  The function is called from Next
+// at most once per interval, and always for the final packet.  If the interval
+// is less than 1, the function is called for every packet.
+func WithProgress(fn func(Progress), interval time.Duration) Option {
+	return optionFunc(func(s *Packetizer) error {
+		if interval < 1 {
+			interval = 0
+		}
+		s.onProgress = fn
+		s.progressInterval = interval
+		return nil
+	})
+}
+
+// WithObserver sets the Observer that receives the events of the stream, such
+// as each packet emitted and encoding fallbacks.  This is optional.
+func WithObserver(o Observer) Option {
+	return optionFunc(func(s *Packetizer) error {
+		s.observer = o
+		return nil
+	})
+}
+
+// WithFieldLocation sets where the SSP control fields are placed in each
+// packet: the Headers (the default), the Metadata, or both.  The Assembler
+// accepts the fields from either location.  This is optional.
+func WithFieldLocation(location FieldLocation) Option {
+	return optionFunc(func(s *Packetizer) error {
+		if !location.isValid() {
+			return fmt.Errorf("%w: invalid field location %d", ErrInvalidInput, location)
+		}
+		s.location = location
+		return nil
+	})
+}
+
 // WithUpdateTransactionUUID sets the function to generate a new transaction
 // UUID for each packet.  This is optional.  If not set, the TransactionUUID
 // from the input message is preserved in the output packets.
@@ -109,6 +318,48 @@ func finalize() Option {
 			return fmt.Errorf("%w: encoding is invalid", ErrInvalidInput)
 		}
 
+		if !validString.MatchString(s.metadata.Name) {
+			return fmt.Errorf("%w: name contains invalid characters", ErrInvalidInput)
+		}
+
+		if !validString.MatchString(s.metadata.ContentType) {
+			return fmt.Errorf("%w: content type contains invalid characters", ErrInvalidInput)
+		}
+
+		if f, ok := s.stream.(*os.File); ok {
+			s.metadata = s.metadata.withFile(f)
+		}
+
+		s.deadlines = deadlines(s.stream)
+
+		if s.estimatedSize == 0 {
+			if n, ok := detectLength(s.stream); ok {
+				s.estimatedSize = uint64(n) // nolint:gosec
+			}
+			if s.refreshLength {
+				s.sizer = fileSizer(s.stream)
+			}
+		}
+
+		if s.keys != nil {
+			aead, err := newAEAD(s.keys, s.keyID)
+			if err != nil {
+				return err
+			}
+			s.aead = aead
+		}
+
+		if s.maxPayloadSize > 0 {
+			room := s.maxPayloadSize
+			if s.aead != nil {
+				room -= s.aead.NonceSize() + s.aead.Overhead()
+			}
+			if room < 1 {
+				return fmt.Errorf("%w: max payload size leaves no room for data", ErrInvalidInput)
+			}
+			s.maxPacketSize = min(s.maxPacketSize, room)
+		}
+
 		return nil
 	})
 }
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"time"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// Packet is a single SSP packet: a WRP message and the values of its SSP
+// headers.  It allows proxies, loggers and custom assemblers to work with
+// packets directly.  The Headers of the embedded message only contain the
+// headers that are not SSP headers.
+type Packet struct {
+	wrp.Message
+	StreamID              string
+	StreamPacketNumber    int64
+	StreamEstimatedLength uint64
+	StreamFinalPacket     string
+	StreamEncoding        Encoding
+	StreamKeyID           string
+	StreamSignature       string
+
+	// StreamTotalPackets and StreamTotalLength are only present on the final
+	// packet.  A StreamTotalPackets of 0 means neither is present.
+	StreamTotalPackets int64
+	StreamTotalLength  uint64
+
+	// StreamTrailers are only present on the final packet.
+	StreamTrailers map[string]string
+
+	// The descriptive metadata of the stream is sent with every packet.
+	StreamName        string
+	StreamContentType string
+	StreamModTime     time.Time
+}
+
+var _ wrp.Union = &Packet{}
+
+// Parse returns the packet in the message.  If the message is not an SSP
+// message, wrp.ErrNotHandled is returned.  The headers are parsed leniently;
+// use ValidateHeaders to check them against the grammar in protocol.md.
+func Parse(msg wrp.Message, validators ...wrp.Processor) (*Packet, error) {
+	if msg.Type != wrp.SimpleEventMessageType || !Is(&msg, validators...) {
+		return nil, wrp.ErrNotHandled
+	}
+
+	var p Packet
+	if err := p.From(&msg, validators...); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// ToMessage returns the WRP message for the packet, with the SSP headers
+// following any other headers.  The message type is always SimpleEvent.
+func (p *Packet) ToMessage(validators ...wrp.Processor) (*wrp.Message, error) {
+	tmp := *p
+	tmp.Type = wrp.SimpleEventMessageType
+
+	var msg wrp.Message
+	if err := tmp.To(&msg, validators...); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+// IsFinal reports if the packet is the final packet of the stream.
+func (p *Packet) IsFinal() bool {
+	return p.StreamFinalPacket != ""
+}
+
+// MsgType implements wrp.Union.
+func (p *Packet) MsgType() wrp.MessageType {
+	return (*simpleStreamingMessage)(p).MsgType()
+}
+
+// From implements wrp.Union.
+func (p *Packet) From(msg *wrp.Message, validators ...wrp.Processor) error {
+	return (*simpleStreamingMessage)(p).From(msg, validators...)
+}
+
+// To implements wrp.Union.
+func (p *Packet) To(msg *wrp.Message, validators ...wrp.Processor) error {
+	return (*simpleStreamingMessage)(p).To(msg, validators...)
+}
+
+// Validate implements wrp.Union.
+func (p *Packet) Validate(validators ...wrp.Processor) error {
+	return (*simpleStreamingMessage)(p).Validate(validators...)
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     wrp.Message
+		want    *Packet
+		wantErr error
+	}{
+		{
+			name: "packet",
+			msg: wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers: []string{
+					"other: value",
+					"stream-id: 123",
+					"stream-packet-number: 2",
+					"stream-final-packet: EOF",
+					"stream-encoding: gzip",
+					"stream-total-packets: 3",
+					"stream-total-length: 42",
+					"stream-trailer-count: 7",
+				},
+				Payload: []byte("data"),
+			},
+			want: &Packet{
+				Message: wrp.Message{
+					Type:        wrp.SimpleEventMessageType,
+					Source:      "mac:112233445566",
+					Destination: "event:test",
+					Headers:     []string{"other: value"},
+					Payload:     []byte("data"),
+				},
+				StreamID:           "123",
+				StreamPacketNumber: 2,
+				StreamFinalPacket:  "eof",
+				StreamEncoding:     EncodingGzip,
+				StreamTotalPackets: 3,
+				StreamTotalLength:  42,
+				StreamTrailers:     map[string]string{"count": "7"},
+			},
+		}, {
+			name: "not an SSP message",
+			msg: wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers:     []string{"other: value"},
+			},
+			wantErr: wrp.ErrNotHandled,
+		}, {
+			name: "not a simple event",
+			msg: wrp.Message{
+				Type:        wrp.SimpleRequestResponseMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers:     []string{"stream-id: 123", "stream-packet-number: 0"},
+			},
+			wantErr: wrp.ErrNotHandled,
+		}, {
+			name: "invalid packet number",
+			msg: wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Headers:     []string{"stream-id: 123", "stream-packet-number: abc"},
+			},
+			wantErr: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.IsFinal())
+		})
+	}
+}
+
+func TestPacket_ToMessage(t *testing.T) {
+	packet := Packet{
+		Message: wrp.Message{
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers:     []string{"other: value", "stream-id: stale"},
+			Payload:     []byte("Hello"),
+		},
+		StreamID:           "123",
+		StreamPacketNumber: 1,
+		StreamName:         "hello.txt",
+	}
+	assert.False(t, packet.IsFinal())
+
+	msg, err := packet.ToMessage()
+	require.NoError(t, err)
+	assert.Equal(t, &wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+		Headers: []string{
+			"other: value",
+			"stream-id: 123",
+			"stream-packet-number: 1",
+			"stream-name: hello.txt",
+		},
+		Payload: []byte("Hello"),
+	}, msg)
+
+	// The packet is not changed.
+	assert.Equal(t, wrp.MessageType(0), packet.Type)
+
+	// Round trip.
+	got, err := Parse(*msg)
+	require.NoError(t, err)
+	packet.Type = wrp.SimpleEventMessageType
+	packet.Headers = []string{"other: value"}
+	assert.Equal(t, &packet, got)
+
+	// Invalid packets are rejected.
+	packet.StreamID = "not valid"
+	_, err = packet.ToMessage()
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestPacket_Assembler(t *testing.T) {
+	var assembler Assembler
+
+	for i, chunk := range []string{"Hello", "World"} {
+		packet := Packet{
+			Message: wrp.Message{
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+				Payload:     []byte(chunk),
+			},
+			StreamID:           "123",
+			StreamPacketNumber: int64(i),
+		}
+		if i == 1 {
+			packet.StreamFinalPacket = "eof"
+		}
+
+		msg, err := packet.ToMessage()
+		require.NoError(t, err)
+		assert.True(t, Is(&packet))
+		require.NoError(t, assembler.ProcessWRP(context.Background(), *msg))
+	}
+
+	got, err := io.ReadAll(&assembler)
+	require.NoError(t, err)
+	assert.Equal(t, "HelloWorld", string(got))
+}
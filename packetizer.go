@@ -5,8 +5,12 @@ package wrpssp
 
 import (
 	"context"
+	"crypto/cipher"
 	"errors"
 	"io"
+	"iter"
+	"sync"
+	"time"
 
 	"github.com/xmidt-org/wrp-go/v5"
 )
@@ -18,10 +22,33 @@ type Packetizer struct {
 	id                  string
 	currentPacketNumber int64
 	maxPacketSize       int
+	maxPayloadSize      int
 	encoding            Encoding
 	txGen               func() (string, error)
 	estimatedSize       uint64
+	refreshLength       bool
+	sizer               func() (int64, bool)
+	metadata            StreamMetadata
+	maxLatency          time.Duration
+	interruptible       bool
+	deadlines           deadliner
+	async               *asyncReader
+	keyID               string
+	keys                KeyProvider
+	aead                cipher.AEAD
+	signer              Signer
+	reason              func(error) Reason
+	trailers            func() (map[string]string, error)
+	totalLength         uint64
+	onProgress          func(Progress)
+	progressInterval    time.Duration
+	observer            Observer
+	location            FieldLocation
 	outcome             error
+
+	pm       sync.Mutex // Guards the progress, which is read by Progress
+	progress progressTracker
+	done     bool
 }
 
 // New creates a new Packetizer with the given options.  Similar to io.Reader and
@@ -56,14 +83,54 @@ func New(opts ...Option) (*Packetizer, error) {
 // populated WRP message or an error.  The error io.EOF will be returned when
 // the stream is exhausted.  Other errors may be returned if those are
 // encountered during the processing.
+//
+// If the ctx was canceled by a CancelWatcher, the final packet is produced
+// with the canceled reason and an error wrapping ErrCanceledByReceiver is
+// returned.
 func (p *Packetizer) Next(ctx context.Context, msg wrp.Message, validators ...wrp.Processor) (*wrp.Message, error) {
+	chunk := func() ([]byte, error) {
+		return p.readChunk(ctx)
+	}
+
 	// Check outcome first so sticky stream state takes precedence over context
-	if p.outcome != nil {
-		return nil, p.outcome
+	if p.outcome == nil && ctx.Err() != nil {
+		cause := context.Cause(ctx)
+		if !errors.Is(cause, ErrCanceledByReceiver) {
+			return nil, cause
+		}
+
+		// The receiver expects a final packet in response to its cancel.
+		chunk = func() ([]byte, error) {
+			return nil, cause
+		}
 	}
 
-	if ctx.Err() != nil {
-		return nil, ctx.Err()
+	return p.emit(msg, validators, chunk)
+}
+
+// Abort ends the stream early.  The populated WRP message is the final packet
+// of the stream, contains no further data from the stream, and carries the
+// aborted reason with the provided reason as the message.  The message is
+// sanitized before it is sent.  The msg and validators are used the same way
+// as by Next.  Abort does no I/O, so the ctx is not checked and a stream can
+// be aborted after its context is canceled.
+//
+// The returned error wraps ErrAborted when the final packet is produced.  If
+// generating the transaction UUID, signing or validating the packet fails, no
+// packet is produced and that error is returned instead.  Either way the
+// stream has ended, and all subsequent calls to Next and Abort return the
+// sticky error.  If the stream has already ended, no packet is produced and
+// the sticky error is returned.
+func (p *Packetizer) Abort(_ context.Context, msg wrp.Message, reason string, validators ...wrp.Processor) (*wrp.Message, error) {
+	return p.emit(msg, validators, func() ([]byte, error) {
+		return nil, &abortedError{err: ErrAborted, message: reason}
+	})
+}
+
+// emit produces the next packet using the data and error returned by chunk.
+func (p *Packetizer) emit(msg wrp.Message, validators []wrp.Processor, chunk func() ([]byte, error)) (*wrp.Message, error) {
+	if p.outcome != nil {
+		return nil, p.outcome
 	}
 
 	var tx string
@@ -76,29 +143,121 @@ func (p *Packetizer) Next(ctx context.Context, msg wrp.Message, validators ...wr
 		}
 	}
 
-	ssm := p.nextRaw(ctx, tx, msg)
+	ssm := p.nextRaw(tx, msg, chunk)
+
+	if p.signer != nil {
+		if err := ssm.sign(p.signer); err != nil {
+			p.outcome = err
+			return nil, err
+		}
+	}
 
 	var out wrp.Message
-	if err := ssm.To(&out, validators...); err != nil {
+	if err := ssm.to(&out, p.location, validators...); err != nil {
 		return nil, err
 	}
 
+	p.recordProgress(len(out.Payload))
+	p.observe(Event{
+		Kind:         EventPacketEmitted,
+		StreamID:     ssm.StreamID,
+		PacketNumber: ssm.StreamPacketNumber,
+		Size:         len(out.Payload),
+		Encoding:     ssm.StreamEncoding,
+	})
+	if p.outcome != nil {
+		err := p.outcome
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		p.observe(streamEndEvent(ssm.StreamID, ssm.StreamPacketNumber, err))
+	}
+
 	return &out, p.outcome
 }
 
-func (p *Packetizer) nextRaw(ctx context.Context, tx string, msg wrp.Message) *simpleStreamingMessage {
-	buf, err := p.readChunk(ctx)
+// recordProgress records the packet produced and calls the progress callback
+// if it is due.
+func (p *Packetizer) recordProgress(encoded int) {
+	now := time.Now()
+
+	p.pm.Lock()
+	p.progress.packet(now, encoded, p.estimatedSize)
+	p.progress.bytes = p.totalLength
+	p.done = p.outcome != nil
+	p.progress.complete = errors.Is(p.outcome, io.EOF)
+
+	due := p.onProgress != nil && p.progress.due(now, p.progressInterval, p.done)
+	progress := p.progress.snapshot(now, p.done)
+	p.pm.Unlock()
+
+	if due {
+		p.onProgress(progress)
+	}
+}
+
+// Packets returns an iterator over the packets of the stream.  Every packet
+// before the final packet is yielded with a nil error.  The iteration stops
+// after the final packet is yielded.
+//
+// When the stream ends with io.EOF, the final packet is yielded with a nil
+// error.  When it ends with any other error, the final packet is yielded along
+// with that error.  The packet should still be sent so the receiver knows the
+// stream has ended.  If the error prevented a packet from being produced, the
+// packet yielded is nil.
+//
+// The msg and validators are used the same way as by Next.
+func (p *Packetizer) Packets(ctx context.Context, msg wrp.Message, validators ...wrp.Processor) iter.Seq2[*wrp.Message, error] {
+	return func(yield func(*wrp.Message, error) bool) {
+		for {
+			out, err := p.Next(ctx, msg, validators...)
+			if err == nil {
+				if !yield(out, nil) {
+					return
+				}
+				continue
+			}
+
+			if errors.Is(err, io.EOF) {
+				if out != nil {
+					yield(out, nil)
+				}
+				return
+			}
+
+			yield(out, err)
+			return
+		}
+	}
+}
+
+func (p *Packetizer) nextRaw(tx string, msg wrp.Message, chunk func() ([]byte, error)) *simpleStreamingMessage {
+	buf, err := chunk()
 
 	var out simpleStreamingMessage
 
 	out.Message = msg
 
+	if errors.Is(err, io.EOF) && p.trailers != nil {
+		out.StreamTrailers, err = p.trailerHeaders()
+	}
+
 	p.outcome = err
 
+	p.totalLength += uint64(len(buf))
+	p.refreshEstimatedLength()
+
 	out.StreamID = p.id
 	out.StreamPacketNumber = p.currentPacketNumber
 	out.StreamEstimatedLength = p.estimatedSize
+	out.StreamName = p.metadata.Name
+	out.StreamContentType = p.metadata.ContentType
+	out.StreamModTime = p.metadata.ModTime
 	out.StreamFinalPacket = p.outcomeToString()
+	if p.outcome != nil {
+		out.StreamTotalPackets = p.currentPacketNumber + 1
+		out.StreamTotalLength = p.totalLength
+	}
 	if tx != "" {
 		out.TransactionUUID = tx
 	}
@@ -107,21 +266,51 @@ func (p *Packetizer) nextRaw(ctx context.Context, tx string, msg wrp.Message) *s
 	p.currentPacketNumber++
 
 	p.compress(&out)
+	p.encrypt(&out)
 
 	return &out
 }
 
-// outcomeToString converts the current outcome error into its string
-// representation for inclusion in the WRP message.  If there is no outcome, an
-// empty string is returned.
+// refreshEstimatedLength updates the estimated length from the current size of
+// the source, if enabled.  The estimate is never less than what has been read.
+func (p *Packetizer) refreshEstimatedLength() {
+	if p.sizer == nil {
+		return
+	}
+
+	if n, ok := p.sizer(); ok {
+		p.estimatedSize = max(uint64(n), p.totalLength) // nolint:gosec
+	}
+}
+
+// trailerHeaders returns the trailers to send with the final packet.  If the
+// trailers cannot be produced or are invalid, the error is returned so the
+// stream ends with it instead of io.EOF.
+func (p *Packetizer) trailerHeaders() (map[string]string, error) {
+	trailers, err := p.trailers()
+	if err == nil {
+		trailers, err = normalizeTrailers(trailers)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return trailers, io.EOF
+}
+
+// outcomeToString converts the current outcome error into the structured
+// reason for inclusion in the WRP message.  If there is no outcome, an empty
+// string is returned.
 func (p *Packetizer) outcomeToString() string {
 	switch {
 	case p.outcome == nil:
 		return ""
 	case errors.Is(p.outcome, io.EOF):
 		return "EOF"
+	case p.reason != nil:
+		return p.reason(p.outcome).String()
 	default:
-		return p.outcome.Error()
+		return DefaultFinalReason(p.outcome).String()
 	}
 }
 
@@ -142,16 +331,48 @@ func (p *Packetizer) compress(msg *simpleStreamingMessage) {
 	payload, err := p.encoding.encode(msg.Payload)
 	if err != nil {
 		// On encoding error, fall back to identity encoding for all subsequent packets
+		p.observe(Event{
+			Kind:         EventEncodingFallback,
+			StreamID:     msg.StreamID,
+			PacketNumber: msg.StreamPacketNumber,
+			Encoding:     p.encoding,
+			Err:          err,
+		})
 		p.encoding = EncodingIdentity
 		msg.StreamEncoding = EncodingIdentity
 		return
 	}
 
+	// Data that does not compress would outgrow the payload size limit.
+	if p.maxPayloadSize > 0 && len(payload) > len(msg.Payload) {
+		msg.StreamEncoding = EncodingIdentity
+		return
+	}
+
 	msg.StreamEncoding = p.encoding
 	msg.Payload = payload
 }
 
+// encrypt seals the message payload if encryption is configured.  Every packet
+// is encrypted, including packets with an empty payload, so the receiver can
+// authenticate all of them.
+func (p *Packetizer) encrypt(msg *simpleStreamingMessage) {
+	if p.aead == nil {
+		return
+	}
+
+	msg.StreamKeyID = p.keyID
+	msg.Payload = seal(p.aead, msg.StreamID, msg.StreamPacketNumber, msg.Payload)
+}
+
 func (p *Packetizer) readChunk(ctx context.Context) ([]byte, error) {
+	if p.maxLatency > 0 || (p.interruptible && p.deadlines == nil) {
+		if p.async == nil {
+			p.async = newAsyncReader(p.stream, p.deadlines, p.maxPacketSize)
+		}
+		return p.async.readChunk(ctx, p.maxPacketSize, p.maxLatency)
+	}
+
 	buf := make([]byte, p.maxPacketSize)
 	var got int
 	var err error
@@ -159,11 +380,11 @@ func (p *Packetizer) readChunk(ctx context.Context) ([]byte, error) {
 	// Read until buffer full or error
 	for err == nil && got < len(buf) {
 		if ctx.Err() != nil {
-			return buf[:got], ctx.Err()
+			return buf[:got], context.Cause(ctx)
 		}
 
 		var n int
-		n, err = p.stream.Read(buf[got:])
+		n, err = read(ctx, p.stream, p.deadlines, buf[got:])
 		got += n
 
 		// Detect buggy readers that return (0, nil) without making progress
@@ -4,10 +4,14 @@
 package wrpssp
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"net"
+	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -275,3 +279,135 @@ func TestPacketizer_ContextCancelPreservesStreamState(t *testing.T) {
 	// Verify packet numbers increment
 	assert.Contains(t, got2.Headers, "stream-packet-number: 1")
 }
+
+// noDeadlineReader implements SetReadDeadline, but like an *os.File for a
+// regular file, it does not support read deadlines.
+type noDeadlineReader struct {
+	io.Reader
+}
+
+func (noDeadlineReader) SetReadDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+// TestPacketizer_InterruptBlockedRead verifies that a Read blocked on a source
+// that never produces more data is interrupted when the context is canceled.
+func TestPacketizer_InterruptBlockedRead(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	tests := []struct {
+		name          string
+		interruptible bool
+		pipe          func() (io.Reader, io.WriteCloser)
+	}{
+		{
+			name: "reader with read deadlines",
+			pipe: func() (io.Reader, io.WriteCloser) {
+				return net.Pipe()
+			},
+		}, {
+			name:          "reader without read deadlines",
+			interruptible: true,
+			pipe: func() (io.Reader, io.WriteCloser) {
+				return io.Pipe()
+			},
+		}, {
+			name:          "reader that returns os.ErrNoDeadline",
+			interruptible: true,
+			pipe: func() (io.Reader, io.WriteCloser) {
+				r, w := io.Pipe()
+				return noDeadlineReader{r}, w
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w := tt.pipe()
+			defer w.Close()
+
+			packetizer, err := New(
+				ID("blocked"),
+				Reader(r),
+				MaxPacketSize(10),
+				WithEncoding(EncodingIdentity),
+				InterruptibleReads(tt.interruptible),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			type result struct {
+				msg *wrp.Message
+				err error
+			}
+			resultCh := make(chan result, 1)
+			go func() {
+				msg, err := packetizer.Next(ctx, in)
+				resultCh <- result{msg, err}
+			}()
+
+			// The write completes once the data has been read, after which the
+			// source blocks forever.
+			_, err = w.Write([]byte("abc"))
+			require.NoError(t, err)
+
+			// Give Next time to block in the next Read before canceling.
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+
+			var res result
+			select {
+			case res = <-resultCh:
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "Next was not interrupted")
+			}
+
+			assert.ErrorIs(t, res.err, context.Canceled)
+			require.NotNil(t, res.msg)
+			// A background read may finish after Next has stopped waiting, so
+			// the data read is not required to be in the final packet.
+			assert.True(t, bytes.HasPrefix([]byte("abc"), res.msg.Payload))
+			assert.Contains(t, res.msg.Headers, "stream-final-packet: canceled")
+
+			// The outcome is sticky.
+			got, err := packetizer.Next(context.Background(), in)
+			assert.Nil(t, got)
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
+
+// TestPacketizer_DeadlineReaderCompletes verifies that a stream supporting read
+// deadlines is read normally when the context is not canceled.
+func TestPacketizer_DeadlineReaderCompletes(t *testing.T) {
+	r, w := net.Pipe()
+
+	packetizer, err := New(
+		ID("deadline"),
+		Reader(r),
+		MaxPacketSize(10),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_, _ = w.Write([]byte("Hello"))
+		_ = w.Close()
+	}()
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	got, err := packetizer.Next(context.Background(), in)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NotNil(t, got)
+	assert.Equal(t, []byte("Hello"), got.Payload)
+}
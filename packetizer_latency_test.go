@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// chanReader returns each chunk sent on the channel as the result of a single
+// Read call, simulating a live source that produces data at its own pace.  The
+// stream ends with io.EOF when the channel is closed.
+type chanReader struct {
+	chunks chan []byte
+}
+
+func (r *chanReader) Read(p []byte) (int, error) {
+	chunk, ok := <-r.chunks
+	if !ok {
+		return 0, io.EOF
+	}
+
+	return copy(p, chunk), nil
+}
+
+func TestPacketizer_MaxLatency(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	t.Run("partial packet is sent when the latency expires", func(t *testing.T) {
+		src := &chanReader{chunks: make(chan []byte, 4)}
+		packetizer, err := New(
+			ID("latency"),
+			Reader(src),
+			MaxPacketSize(10),
+			MaxLatency(10*time.Millisecond),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		src.chunks <- []byte("abc")
+
+		got, err := packetizer.Next(context.Background(), in)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, []byte("abc"), got.Payload)
+
+		src.chunks <- []byte("def")
+		close(src.chunks)
+
+		got, err = packetizer.Next(context.Background(), in)
+		assert.ErrorIs(t, err, io.EOF)
+		require.NotNil(t, got)
+		assert.Equal(t, []byte("def"), got.Payload)
+		assert.Contains(t, got.Headers, "stream-final-packet: eof")
+	})
+
+	t.Run("no bytes are lost across packet boundaries", func(t *testing.T) {
+		src := &chanReader{chunks: make(chan []byte, 4)}
+		packetizer, err := New(
+			ID("latency"),
+			Reader(src),
+			MaxPacketSize(5),
+			MaxLatency(time.Hour),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		src.chunks <- []byte("abc")
+		src.chunks <- []byte("defg")
+		src.chunks <- []byte("hij")
+		close(src.chunks)
+
+		var payloads []string
+		for msg, err := range packetizer.Packets(context.Background(), in) {
+			require.NoError(t, err)
+			payloads = append(payloads, string(msg.Payload))
+		}
+
+		assert.Equal(t, []string{"abcde", "fghij", ""}, payloads)
+	})
+
+	t.Run("context canceled while waiting for data", func(t *testing.T) {
+		src := &chanReader{chunks: make(chan []byte)}
+		packetizer, err := New(
+			ID("latency"),
+			Reader(src),
+			MaxPacketSize(10),
+			MaxLatency(time.Hour),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		resultCh := make(chan *wrp.Message, 1)
+		errCh := make(chan error, 1)
+		go func() {
+			msg, err := packetizer.Next(ctx, in)
+			resultCh <- msg
+			errCh <- err
+		}()
+
+		// Once the source has been read from, Next is waiting for data.  The
+		// source never produces more data, so only cancellation ends the call.
+		src.chunks <- []byte("abc")
+		cancel()
+
+		got := <-resultCh
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+		require.NotNil(t, got)
+		assert.Contains(t, got.Headers, "stream-final-packet: canceled")
+		close(src.chunks)
+	})
+
+	t.Run("a latency less than 1 disables the limit", func(t *testing.T) {
+		packetizer, err := New(
+			ID("latency"),
+			Reader(&chanReader{}),
+			MaxLatency(-1),
+		)
+		require.NoError(t, err)
+		assert.Zero(t, packetizer.maxLatency)
+	})
+}
+
+func TestPacketizer_MaxLatencyNoProgressReader(t *testing.T) {
+	packetizer, err := New(
+		ID("test"),
+		Reader(&noProgressReader{}),
+		MaxPacketSize(10),
+		MaxLatency(time.Hour),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	got, err := packetizer.Next(context.Background(), in)
+	assert.ErrorIs(t, err, io.ErrNoProgress)
+	require.NotNil(t, got)
+	assert.Empty(t, got.Payload)
+}
+
+// stallReader blocks every Read until a read deadline in the past is set,
+// like a pipe that never produces data.  If err is set, SetReadDeadline
+// returns it instead.
+type stallReader struct {
+	m       sync.Mutex
+	expired chan struct{}
+	err     error
+}
+
+func (r *stallReader) wait() chan struct{} {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.expired == nil {
+		r.expired = make(chan struct{})
+	}
+	return r.expired
+}
+
+func (r *stallReader) Read([]byte) (int, error) {
+	<-r.wait()
+	return 0, os.ErrDeadlineExceeded
+}
+
+func (r *stallReader) SetReadDeadline(t time.Time) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	expired := r.wait()
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	switch {
+	case t.IsZero():
+		select {
+		case <-expired:
+			r.expired = nil
+		default:
+		}
+	case t.Before(time.Now()):
+		select {
+		case <-expired:
+		default:
+			close(expired)
+		}
+	}
+	return nil
+}
+
+// asyncReaders returns the number of asyncReader goroutines running.
+func asyncReaders() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return bytes.Count(buf, []byte("(*asyncReader).run("))
+}
+
+func TestPacketizer_MaxLatencyNoLeak(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	stopped := func() bool {
+		return asyncReaders() == 0
+	}
+
+	t.Run("breaking out of Packets", func(t *testing.T) {
+		packetizer, err := New(
+			ID("leak"),
+			Reader(bytes.NewReader([]byte("Hello World"))),
+			MaxPacketSize(6),
+			MaxLatency(time.Hour),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		for msg, err := range packetizer.Packets(context.Background(), in) {
+			require.NoError(t, err)
+			assert.Equal(t, []byte("Hello "), msg.Payload)
+			break
+		}
+		assert.Eventually(t, stopped, time.Second, time.Millisecond)
+
+		// Iteration resumes where it left off.
+		got, err := packetizer.Next(context.Background(), in)
+		assert.ErrorIs(t, err, io.EOF)
+		require.NotNil(t, got)
+		assert.Equal(t, []byte("World"), got.Payload)
+		assert.Eventually(t, stopped, time.Second, time.Millisecond)
+	})
+
+	t.Run("no more calls after the context is canceled", func(t *testing.T) {
+		packetizer, err := New(
+			ID("leak"),
+			Reader(&stallReader{}),
+			MaxPacketSize(10),
+			MaxLatency(time.Hour),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = packetizer.Next(ctx, in)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Eventually(t, stopped, time.Second, time.Millisecond)
+	})
+
+	t.Run("the packet fails validation", func(t *testing.T) {
+		packetizer, err := New(
+			ID("leak"),
+			Reader(bytes.NewReader([]byte("Hello World"))),
+			MaxPacketSize(6),
+			MaxLatency(time.Hour),
+			WithEncoding(EncodingIdentity),
+		)
+		require.NoError(t, err)
+
+		invalid := in
+		invalid.Destination = ""
+
+		_, err = packetizer.Next(context.Background(), invalid)
+		assert.ErrorIs(t, err, wrp.ErrMessageIsInvalid)
+		assert.Eventually(t, stopped, time.Second, time.Millisecond)
+	})
+}
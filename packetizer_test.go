@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -17,6 +18,35 @@ import (
 	"github.com/xmidt-org/wrp-go/v5"
 )
 
+// packetize returns the packets of the input, which is split into packets of
+// up to 5 bytes.  The opts are applied after the defaults.
+func packetize(t *testing.T, input string, opts ...Option) []wrp.Message {
+	t.Helper()
+
+	opts = append([]Option{
+		ID("test"),
+		Reader(strings.NewReader(input)),
+		MaxPacketSize(5),
+	}, opts...)
+
+	p, err := New(opts...)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	var msgs []wrp.Message
+	for msg, err := range p.Packets(context.Background(), in) {
+		require.NoError(t, err)
+		msgs = append(msgs, *msg)
+	}
+
+	return msgs
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -162,6 +192,8 @@ func TestPacketizer_Next(t *testing.T) {
 						"stream-packet-number: 2",
 						"stream-estimated-total-length: 10",
 						"stream-final-packet: eof",
+						"stream-total-packets: 3",
+						"stream-total-length: 10",
 					},
 				},
 			},
@@ -219,6 +251,8 @@ func TestPacketizer_Next(t *testing.T) {
 						"stream-packet-number: 2",
 						"stream-estimated-total-length: 10",
 						"stream-final-packet: eof",
+						"stream-total-packets: 3",
+						"stream-total-length: 10",
 					},
 				},
 			},
@@ -244,6 +278,7 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 0",
+						"stream-estimated-total-length: 10",
 					},
 					Payload: []byte("Hello"),
 				},
@@ -254,6 +289,7 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 1",
+						"stream-estimated-total-length: 10",
 					},
 					Payload: []byte("World"),
 				},
@@ -264,7 +300,10 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 2",
+						"stream-estimated-total-length: 10",
 						"stream-final-packet: eof",
+						"stream-total-packets: 3",
+						"stream-total-length: 10",
 					},
 				},
 			},
@@ -291,6 +330,7 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 0",
+						"stream-estimated-total-length: 10",
 					},
 					Payload: []byte("Hello"),
 				},
@@ -301,6 +341,7 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 1",
+						"stream-estimated-total-length: 10",
 					},
 					Payload: []byte("World"),
 				},
@@ -311,7 +352,10 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 2",
+						"stream-estimated-total-length: 10",
 						"stream-final-packet: eof",
+						"stream-total-packets: 3",
+						"stream-total-length: 10",
 					},
 				},
 			},
@@ -351,6 +395,8 @@ func TestPacketizer_Next(t *testing.T) {
 						"stream-packet-number: 1",
 						"stream-estimated-total-length: 20",
 						"stream-final-packet: eof",
+						"stream-total-packets: 2",
+						"stream-total-length: 10",
 					},
 					Payload: []byte("orld"),
 				},
@@ -389,7 +435,9 @@ func TestPacketizer_Next(t *testing.T) {
 						"stream-id: 123",
 						"stream-packet-number: 1",
 						"stream-estimated-total-length: 20",
-						"stream-final-packet: unexpected EOF",
+						"stream-final-packet: io-error",
+						"stream-total-packets: 2",
+						"stream-total-length: 7",
 					},
 					Payload: []byte("o"),
 				},
@@ -440,7 +488,10 @@ func TestPacketizer_Next(t *testing.T) {
 					Headers: []string{
 						"stream-id: 123",
 						"stream-packet-number: 0",
+						"stream-estimated-total-length: 10",
 						"stream-final-packet: eof",
+						"stream-total-packets: 1",
+						"stream-total-length: 10",
 						// Note: No stream-encoding header means identity encoding (fallback)
 					},
 					Payload: []byte("HelloWorld"),
@@ -774,6 +825,7 @@ func TestPacketizer_ContextCancellation(t *testing.T) {
 		assert.Equal(t, []string{
 			"stream-id: 123",
 			"stream-packet-number: 0",
+			"stream-estimated-total-length: 10",
 		}, got.Headers)
 
 		// Continue reading the rest
@@ -867,3 +919,237 @@ func TestPacketizer_NoProgressReader(t *testing.T) {
 	reader.mu.Unlock()
 	assert.Equal(t, 1, count, "should only call Read once before detecting no progress")
 }
+
+func TestPacketizer_Packets(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		payloads []string
+		final    string
+		err      error
+	}{
+		{
+			name:     "complete stream",
+			reader:   bytes.NewReader([]byte("HelloWorld!")),
+			payloads: []string{"Hello", "World", "!"},
+			final:    "stream-final-packet: eof",
+		}, {
+			name:     "empty stream",
+			reader:   bytes.NewReader(nil),
+			payloads: []string{""},
+			final:    "stream-final-packet: eof",
+		}, {
+			name: "reader error",
+			reader: &faultyReader{
+				Reader: bytes.NewReader([]byte("HelloWorld")),
+				when:   7,
+			},
+			payloads: []string{"Hello", "Wo"},
+			final:    "stream-final-packet: io-error",
+			err:      io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packetizer, err := New(
+				ID("123"),
+				Reader(tt.reader),
+				MaxPacketSize(5),
+				WithEncoding(EncodingIdentity),
+			)
+			require.NoError(t, err)
+
+			var got []*wrp.Message
+			var errs []error
+			for msg, err := range packetizer.Packets(context.Background(), in) {
+				got = append(got, msg)
+				errs = append(errs, err)
+			}
+
+			require.Equal(t, len(tt.payloads), len(got))
+			for i, msg := range got {
+				require.NotNil(t, msg, "message %d should not be nil", i)
+				assert.Equal(t, tt.payloads[i], string(msg.Payload), "message %d payload", i)
+				if i < len(got)-1 {
+					assert.NoError(t, errs[i], "message %d should not error", i)
+					continue
+				}
+
+				assert.Contains(t, msg.Headers, tt.final)
+				if tt.err == nil {
+					assert.NoError(t, errs[i])
+				} else {
+					assert.ErrorIs(t, errs[i], tt.err)
+				}
+			}
+
+			// The stream is exhausted, so only the sticky error is yielded.
+			for msg, err := range packetizer.Packets(context.Background(), in) {
+				assert.Nil(t, msg)
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPacketizer_PacketsStopEarly(t *testing.T) {
+	packetizer, err := New(
+		ID("123"),
+		Reader(bytes.NewReader([]byte("HelloWorld!"))),
+		MaxPacketSize(5),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	var count int
+	for msg, err := range packetizer.Packets(context.Background(), in) {
+		require.NoError(t, err)
+		require.NotNil(t, msg)
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	// Iteration resumes where it left off.
+	next, err := packetizer.Next(context.Background(), in)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("World"), next.Payload)
+}
+
+func TestPacketizer_PacketsInvalidMessage(t *testing.T) {
+	packetizer, err := New(
+		ID("123"),
+		Reader(bytes.NewReader([]byte("HelloWorld!"))),
+		MaxPacketSize(5),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:   wrp.SimpleEventMessageType,
+		Source: "mac:112233445566",
+		// Missing Destination
+	}
+
+	var count int
+	for msg, err := range packetizer.Packets(context.Background(), in) {
+		assert.Nil(t, msg)
+		assert.ErrorIs(t, err, wrp.ErrMessageIsInvalid)
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestPacketizer_Abort(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	packetizer, err := New(
+		ID("123"),
+		Reader(bytes.NewReader([]byte("HelloWorld!"))),
+		MaxPacketSize(5),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	var assembler Assembler
+
+	first, err := packetizer.Next(context.Background(), in)
+	require.NoError(t, err)
+	require.NoError(t, assembler.ProcessWRP(context.Background(), *first))
+
+	final, err := packetizer.Abort(context.Background(), in, "user canceled\nthe upload")
+	assert.ErrorIs(t, err, ErrAborted)
+	require.NotNil(t, final)
+	assert.Empty(t, final.Payload)
+	assert.Equal(t, []string{
+		"stream-id: 123",
+		"stream-packet-number: 1",
+		"stream-estimated-total-length: 11",
+		"stream-final-packet: aborted: user canceled the upload",
+		"stream-total-packets: 2",
+		"stream-total-length: 5",
+	}, final.Headers)
+	require.NoError(t, assembler.ProcessWRP(context.Background(), *final))
+
+	// The aborted outcome is sticky.
+	got, err := packetizer.Next(context.Background(), in)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, ErrAborted)
+
+	got, err = packetizer.Abort(context.Background(), in, "again")
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, ErrAborted)
+
+	buf, err := io.ReadAll(&assembler)
+	assert.Equal(t, "Hello", string(buf))
+	assert.ErrorIs(t, err, ErrStreamAborted)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestPacketizer_AbortCanceledContext(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	packetizer, err := New(
+		ID("123"),
+		Reader(bytes.NewReader([]byte("HelloWorld!"))),
+		MaxPacketSize(5),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The user gave up, which is when the stream is usually aborted.
+	final, err := packetizer.Abort(ctx, in, "user gave up")
+	assert.ErrorIs(t, err, ErrAborted)
+	require.NotNil(t, final)
+	assert.Contains(t, final.Headers, "stream-final-packet: aborted: user gave up")
+
+	got, err := packetizer.Next(context.Background(), in)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, ErrAborted)
+}
+
+func TestPacketizer_AbortAfterEnd(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+	}
+
+	packetizer, err := New(
+		ID("123"),
+		Reader(bytes.NewReader([]byte("Hello"))),
+		WithEncoding(EncodingIdentity),
+	)
+	require.NoError(t, err)
+
+	got, err := packetizer.Next(context.Background(), in)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NotNil(t, got)
+
+	got, err = packetizer.Abort(context.Background(), in, "too late")
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"time"
+)
+
+// Progress is a snapshot of the progress of a stream.
+type Progress struct {
+	// Packets is the number of packets sent by the Packetizer, or received by
+	// the Assembler.  Duplicate packets are not counted.
+	Packets int64
+
+	// Bytes is the number of bytes of the stream read from the source by the
+	// Packetizer, or read from the Assembler.
+	Bytes uint64
+
+	// EncodedBytes is the number of payload bytes sent by the Packetizer, or
+	// received by the Assembler, after encoding and encryption.
+	EncodedBytes uint64
+
+	// EstimatedTotal is the stream-estimated-total-length of the stream, or 0
+	// if it is not known.
+	EstimatedTotal uint64
+
+	// Percent is Bytes as a percentage of EstimatedTotal, limited to 100.  It
+	// is 0 if EstimatedTotal is not known, and 100 once the stream has ended
+	// with io.EOF.  A stream that ends with any other error keeps the
+	// percentage reached.
+	Percent float64
+
+	// Elapsed is the time since the first packet was sent or received.
+	Elapsed time.Duration
+
+	// BytesPerSecond is Bytes divided by Elapsed.
+	BytesPerSecond float64
+
+	// Done is true once the stream has ended.
+	Done bool
+}
+
+// progressTracker counts the progress of a stream and decides when to call
+// the progress callback.  It is not safe for concurrent use.
+type progressTracker struct {
+	started  time.Time
+	notified time.Time
+	finished bool
+	complete bool // The stream ended with io.EOF
+
+	packets   int64
+	bytes     uint64
+	encoded   uint64
+	estimated uint64
+}
+
+// packet records a packet with the encoded payload size and estimated length.
+func (t *progressTracker) packet(now time.Time, encoded int, estimated uint64) {
+	if t.started.IsZero() {
+		t.started = now
+	}
+
+	t.packets++
+	t.encoded += uint64(encoded) // nolint:gosec
+	if estimated > 0 {
+		t.estimated = estimated
+	}
+}
+
+// snapshot returns the progress as of now.
+func (t *progressTracker) snapshot(now time.Time, done bool) Progress {
+	p := Progress{
+		Packets:        t.packets,
+		Bytes:          t.bytes,
+		EncodedBytes:   t.encoded,
+		EstimatedTotal: t.estimated,
+		Done:           done,
+	}
+
+	if !t.started.IsZero() {
+		p.Elapsed = now.Sub(t.started)
+	}
+
+	if p.Elapsed > 0 {
+		p.BytesPerSecond = float64(p.Bytes) / p.Elapsed.Seconds()
+	}
+
+	switch {
+	case done && t.complete:
+		p.Percent = 100
+	case p.EstimatedTotal > 0:
+		p.Percent = min(100, 100*float64(p.Bytes)/float64(p.EstimatedTotal))
+	}
+
+	return p
+}
+
+// due reports if the progress callback should be called now, and records that
+// it was.  The callback is called at most once per interval, and once when the
+// stream is done.
+func (t *progressTracker) due(now time.Time, interval time.Duration, done bool) bool {
+	if t.finished {
+		return false
+	}
+
+	if done {
+		t.finished = true
+		return true
+	}
+
+	if !t.notified.IsZero() && now.Sub(t.notified) < interval {
+		return false
+	}
+
+	t.notified = now
+	return true
+}
+
+// Progress returns a snapshot of the progress of the stream.  It is safe to
+// call concurrently with Next.
+func (p *Packetizer) Progress() Progress {
+	p.pm.Lock()
+	defer p.pm.Unlock()
+
+	return p.progress.snapshot(time.Now(), p.done)
+}
+
+// Progress returns a snapshot of the progress of the stream.  It is safe to
+// call concurrently with Read and ProcessWRP.
+func (a *Assembler) Progress() Progress {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	return a.progress.snapshot(time.Now(), a.done)
+}
+
+// progressDue returns the progress to report to OnProgress, if it is due.
+// Must be called with the lock held.
+func (a *Assembler) progressDue() (Progress, bool) {
+	if a.OnProgress == nil {
+		return Progress{}, false
+	}
+
+	now := time.Now()
+	if !a.progress.due(now, a.ProgressInterval, a.done) {
+		return Progress{}, false
+	}
+
+	return a.progress.snapshot(now, a.done), true
+}
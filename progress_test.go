@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestProgressTracker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var tracker progressTracker
+	assert.Equal(t, Progress{}, tracker.snapshot(start, false))
+
+	tracker.packet(start, 40, 200)
+	tracker.bytes = 50
+	assert.Equal(t, Progress{
+		Packets:        1,
+		Bytes:          50,
+		EncodedBytes:   40,
+		EstimatedTotal: 200,
+		Percent:        25,
+		Elapsed:        2 * time.Second,
+		BytesPerSecond: 25,
+	}, tracker.snapshot(start.Add(2*time.Second), false))
+
+	// The percentage is limited when the estimate is too small.
+	tracker.packet(start, 40, 0)
+	tracker.bytes = 250
+	got := tracker.snapshot(start.Add(time.Second), false)
+	assert.Equal(t, int64(2), got.Packets)
+	assert.Equal(t, uint64(200), got.EstimatedTotal)
+	assert.Equal(t, float64(100), got.Percent)
+
+	// The percentage reached is kept when the stream fails.
+	tracker.bytes = 100
+	got = tracker.snapshot(start.Add(time.Second), true)
+	assert.True(t, got.Done)
+	assert.Equal(t, float64(50), got.Percent)
+
+	tracker.complete = true
+	got = tracker.snapshot(start.Add(time.Second), true)
+	assert.True(t, got.Done)
+	assert.Equal(t, float64(100), got.Percent)
+
+	// The callback is due at most once per interval, and once when done.
+	assert.True(t, tracker.due(start, time.Minute, false))
+	assert.False(t, tracker.due(start.Add(time.Second), time.Minute, false))
+	assert.True(t, tracker.due(start.Add(time.Minute), time.Minute, false))
+	assert.True(t, tracker.due(start.Add(time.Minute), time.Minute, true))
+	assert.False(t, tracker.due(start.Add(time.Hour), time.Minute, true))
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		calls    int
+	}{
+		{
+			name:  "every packet",
+			calls: 3,
+		}, {
+			name:     "first and final packets",
+			interval: time.Hour,
+			calls:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []Progress
+			packetizer, err := New(
+				ID("123"),
+				Reader(strings.NewReader("HelloWorld")),
+				MaxPacketSize(5),
+				WithEncoding(EncodingIdentity),
+				WithProgress(func(p Progress) {
+					sent = append(sent, p)
+				}, tt.interval),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, Progress{}, packetizer.Progress())
+
+			var received []Progress
+			assembler := Assembler{
+				OnProgress: func(p Progress) {
+					received = append(received, p)
+				},
+				ProgressInterval: tt.interval,
+			}
+
+			in := wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+			}
+			for packet, err := range packetizer.Packets(context.Background(), in) {
+				require.NoError(t, err)
+				require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+			}
+
+			require.Len(t, sent, tt.calls)
+			assert.Equal(t, int64(1), sent[0].Packets)
+			assert.Equal(t, uint64(5), sent[0].Bytes)
+			assert.Equal(t, float64(50), sent[0].Percent)
+			assert.False(t, sent[0].Done)
+
+			final := sent[len(sent)-1]
+			assert.Equal(t, int64(3), final.Packets)
+			assert.Equal(t, uint64(10), final.Bytes)
+			assert.Equal(t, uint64(10), final.EncodedBytes)
+			assert.Equal(t, uint64(10), final.EstimatedTotal)
+			assert.True(t, final.Done)
+
+			got := packetizer.Progress()
+			assert.Equal(t, final.Packets, got.Packets)
+			assert.True(t, got.Done)
+
+			// Receiving packets does not end the stream, reading it does.
+			got = assembler.Progress()
+			assert.Equal(t, int64(3), got.Packets)
+			assert.Equal(t, uint64(10), got.EncodedBytes)
+			assert.Zero(t, got.Bytes)
+			assert.False(t, got.Done)
+
+			data, err := io.ReadAll(&assembler)
+			require.NoError(t, err)
+			assert.Equal(t, "HelloWorld", string(data))
+
+			got = assembler.Progress()
+			assert.Equal(t, uint64(10), got.Bytes)
+			assert.Equal(t, float64(100), got.Percent)
+			assert.True(t, got.Done)
+
+			require.NotEmpty(t, received)
+			assert.True(t, received[len(received)-1].Done)
+			assert.Equal(t, uint64(10), received[len(received)-1].Bytes)
+		})
+	}
+}
+
+func TestProgress_Abort(t *testing.T) {
+	var sent []Progress
+	packetizer, err := New(
+		ID("123"),
+		Reader(strings.NewReader("HelloWorld")),
+		MaxPacketSize(5),
+		WithEncoding(EncodingIdentity),
+		WithProgress(func(p Progress) {
+			sent = append(sent, p)
+		}, 0),
+	)
+	require.NoError(t, err)
+
+	var received []Progress
+	assembler := Assembler{
+		OnProgress: func(p Progress) {
+			received = append(received, p)
+		},
+	}
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	packet, err := packetizer.Next(context.Background(), in)
+	require.NoError(t, err)
+	require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+
+	packet, err = packetizer.Abort(context.Background(), in, "shutting down")
+	require.ErrorIs(t, err, ErrAborted)
+	require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+
+	// Half of the stream was sent before it was aborted.
+	require.NotEmpty(t, sent)
+	final := sent[len(sent)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, uint64(5), final.Bytes)
+	assert.Equal(t, float64(50), final.Percent)
+	assert.Equal(t, final.Percent, packetizer.Progress().Percent)
+
+	data, err := io.ReadAll(&assembler)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "Hello", string(data))
+
+	got := assembler.Progress()
+	assert.True(t, got.Done)
+	assert.Equal(t, uint64(5), got.Bytes)
+	assert.Equal(t, float64(50), got.Percent)
+
+	require.NotEmpty(t, received)
+	assert.Equal(t, float64(50), received[len(received)-1].Percent)
+}
+
+func TestAssembler_ProgressConcurrent(t *testing.T) {
+	packetizer, err := New(
+		ID("123"),
+		Reader(strings.NewReader(strings.Repeat("HelloWorld", 100))),
+		MaxPacketSize(10),
+	)
+	require.NoError(t, err)
+
+	var calls int
+	var m sync.Mutex
+	assembler := Assembler{
+		OnProgress: func(p Progress) {
+			m.Lock()
+			defer m.Unlock()
+			calls++
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		in := wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+		}
+		for packet, err := range packetizer.Packets(context.Background(), in) {
+			assert.NoError(t, err)
+			assert.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+			_ = packetizer.Progress()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for !assembler.Progress().Done {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	data, err := io.ReadAll(&assembler)
+	assert.NoError(t, err)
+	assert.Len(t, data, 1000)
+	wg.Wait()
+
+	m.Lock()
+	defer m.Unlock()
+	assert.NotZero(t, calls)
+}
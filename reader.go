@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// deadliner is implemented by readers that support read deadlines, such as
+// net.Conn and *os.File for pipes and devices.
+type deadliner interface {
+	SetReadDeadline(time.Time) error
+}
+
+// deadlines returns the stream as a deadliner if a Read blocked on it can be
+// interrupted by setting a read deadline, or nil if it cannot.  Implementing
+// SetReadDeadline is not enough: some streams, such as an *os.File for a
+// regular file, return os.ErrNoDeadline.  The deadline is cleared to find out.
+func deadlines(stream io.Reader) deadliner {
+	d, ok := stream.(deadliner)
+	if !ok || d.SetReadDeadline(time.Time{}) != nil {
+		return nil
+	}
+	return d
+}
+
+// read reads from the stream.  If d is not nil, a Read that is blocked when
+// the ctx is canceled is interrupted by moving the deadline into the past, and
+// the cause of the ctx cancellation is returned in place of the deadline error.
+// The deadline is cleared afterwards.
+func read(ctx context.Context, stream io.Reader, d deadliner, buf []byte) (int, error) {
+	if d == nil {
+		return stream.Read(buf)
+	}
+
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(interrupted)
+		_ = d.SetReadDeadline(time.Unix(1, 0))
+	})
+
+	n, err := stream.Read(buf)
+	if stop() {
+		return n, err
+	}
+
+	<-interrupted
+	_ = d.SetReadDeadline(time.Time{})
+
+	if err != nil {
+		err = context.Cause(ctx)
+	}
+
+	return n, err
+}
+
+// asyncReader reads from the stream in a background goroutine so the caller
+// can stop waiting for data when a deadline passes or the context is canceled
+// without losing any bytes the stream has produced.  The goroutine only runs
+// while readChunk is waiting for data, so nothing is left running once the
+// caller stops asking for packets.
+type asyncReader struct {
+	stream   io.Reader
+	size     int
+	d        deadliner // nil if reads cannot be interrupted
+	requests chan struct{}
+	results  chan readResult
+
+	// outstanding is set while a read has been requested and its result has
+	// not been received yet.
+	outstanding bool
+
+	// pending holds bytes received from the stream that have not been
+	// placed into a packet yet.
+	pending []byte
+
+	// err is the error the stream returned.  It is only reported once all
+	// pending bytes have been consumed.
+	err error
+
+	m           sync.Mutex // Guards the fields below, which run also uses
+	done        chan struct{}
+	reading     bool
+	interrupted bool
+}
+
+type readResult struct {
+	data []byte
+	err  error
+}
+
+func newAsyncReader(stream io.Reader, d deadliner, size int) *asyncReader {
+	return &asyncReader{
+		stream:   stream,
+		size:     size,
+		d:        d,
+		requests: make(chan struct{}, 1),
+		results:  make(chan readResult, 1),
+	}
+}
+
+// run performs one read for each request until it is stopped or the stream
+// returns an error.  Results are buffered, so a read that completes after the
+// goroutine was stopped is kept for the next call to readChunk.
+func (r *asyncReader) run(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		select {
+		case <-done:
+			return
+		case <-r.requests:
+		}
+
+		r.m.Lock()
+		r.reading = true
+		r.m.Unlock()
+
+		buf := make([]byte, r.size)
+		n, err := r.stream.Read(buf)
+
+		r.m.Lock()
+		r.reading = false
+		interrupted := r.interrupted
+		if interrupted {
+			r.interrupted = false
+			_ = r.d.SetReadDeadline(time.Time{})
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				err = nil
+			}
+		}
+		r.m.Unlock()
+
+		// Detect buggy readers that return (0, nil) without making progress
+		if n == 0 && err == nil && !interrupted {
+			err = io.ErrNoProgress
+		}
+
+		r.results <- readResult{data: buf[:n:n], err: err}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readChunk fills a buffer of up to max bytes.  If latency is positive, the
+// buffer is returned once latency has passed since the first byte was placed
+// in it, even if it is not full.
+func (r *asyncReader) readChunk(ctx context.Context, max int, latency time.Duration) ([]byte, error) {
+	r.start()
+	defer r.stop()
+
+	buf := make([]byte, 0, max)
+
+	var timer *time.Timer
+	var expired <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for len(buf) < max {
+		if len(r.pending) > 0 {
+			n := min(max-len(buf), len(r.pending))
+			buf = append(buf, r.pending[:n]...)
+			r.pending = r.pending[n:]
+
+			// Start the latency budget with the first byte of the packet
+			if timer == nil && latency > 0 {
+				timer = time.NewTimer(latency)
+				expired = timer.C
+			}
+			continue
+		}
+
+		if r.err != nil {
+			return buf, r.err
+		}
+
+		if !r.outstanding {
+			r.requests <- struct{}{}
+			r.outstanding = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return buf, context.Cause(ctx)
+		case <-expired:
+			return buf, nil
+		case res := <-r.results:
+			r.outstanding = false
+			r.pending = res.data
+			r.err = res.err
+		}
+	}
+
+	return buf, nil
+}
+
+// start starts the background goroutine if it is not running.
+func (r *asyncReader) start() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.done == nil {
+		r.done = make(chan struct{})
+		go r.run(r.done)
+	}
+}
+
+// stop stops the background goroutine.  If the stream supports read
+// deadlines, a read that is in progress is interrupted; otherwise the
+// goroutine exits once the read returns.  Either way the bytes read are kept,
+// and the goroutine is started again by the next call to readChunk.
+func (r *asyncReader) stop() {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.done == nil {
+		return
+	}
+
+	close(r.done)
+	r.done = nil
+
+	if r.d != nil && r.reading && !r.interrupted {
+		r.interrupted = true
+		_ = r.d.SetReadDeadline(time.Unix(1, 0))
+	}
+}
@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ReasonCode is the machine readable part of the reason a stream ended early.
+type ReasonCode string
+
+const (
+	// ReasonCanceled indicates the producer canceled the stream.
+	ReasonCanceled ReasonCode = "canceled"
+
+	// ReasonTimeout indicates a deadline passed before the stream completed.
+	ReasonTimeout ReasonCode = "timeout"
+
+	// ReasonIOError indicates reading the source of the stream failed.
+	ReasonIOError ReasonCode = "io-error"
+
+	// ReasonAborted indicates the producer deliberately aborted the stream.
+	ReasonAborted ReasonCode = "aborted"
+)
+
+// maxReasonMessage is the longest message that is included in a reason.
+const maxReasonMessage = 256
+
+var (
+	validReasonCode   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	invalidReasonChar = regexp.MustCompile(`[^A-Za-z0-9 !#$&'()*+,\-./:;=?@\[\\\]_|~]+`)
+)
+
+// Reason is the structured reason carried in the stream-final-packet header
+// when a stream ends early.  It is formatted as "<code>" or
+// "<code>: <message>".
+type Reason struct {
+	// Code is the machine readable reason.  Codes other than the ones defined
+	// by this package may be used, but must only contain [a-z0-9-].
+	Code ReasonCode
+
+	// Message is an optional human readable description.  It is sanitized to
+	// the <string> grammar in protocol.md before being sent, so it should not
+	// contain sensitive details such as internal paths.
+	Message string
+}
+
+// String returns the reason in the form sent in the stream-final-packet
+// header.  An empty or invalid Code is sent as io-error.
+func (r Reason) String() string {
+	code := strings.ToLower(strings.TrimSpace(string(r.Code)))
+	if !validReasonCode.MatchString(code) {
+		code = string(ReasonIOError)
+	}
+
+	msg := sanitizeReason(r.Message)
+	if msg == "" {
+		return code
+	}
+
+	return code + ": " + msg
+}
+
+// ParseReason parses the value of a stream-final-packet header that is not
+// 'eof'.  Values that do not start with a valid code, such as those sent by
+// older senders, result in a Reason with an empty Code and the value as the
+// Message.
+func ParseReason(s string) Reason {
+	s = strings.TrimSpace(s)
+
+	code, msg, _ := strings.Cut(s, ":")
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !validReasonCode.MatchString(code) {
+		return Reason{Message: s}
+	}
+
+	return Reason{
+		Code:    ReasonCode(code),
+		Message: strings.TrimSpace(msg),
+	}
+}
+
+// DefaultFinalReason maps the error that ended a stream early to a Reason.  No
+// message is included so error details are not exposed to the receiver, except
+// for the reason explicitly provided to Packetizer.Abort.
+func DefaultFinalReason(err error) Reason {
+	var abort *abortedError
+	switch {
+	case errors.As(err, &abort):
+		return Reason{Code: ReasonAborted, Message: abort.message}
+	case errors.Is(err, ErrCanceledByReceiver), errors.Is(err, context.Canceled):
+		return Reason{Code: ReasonCanceled}
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return Reason{Code: ReasonTimeout}
+	default:
+		return Reason{Code: ReasonIOError}
+	}
+}
+
+// sanitizeReason limits the message to the characters allowed by the <string>
+// grammar in protocol.md, collapsing any other characters (including newlines)
+// into a single space, and truncates it to a reasonable length.
+func sanitizeReason(s string) string {
+	s = invalidReasonChar.ReplaceAllString(s, " ")
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxReasonMessage {
+		s = strings.TrimSpace(s[:maxReasonMessage])
+	}
+
+	return s
+}
+
+// abortedError marks an error as the reason the producer aborted the stream.
+// The message is the reason provided by the producer for the receiver.
+type abortedError struct {
+	err     error
+	message string
+}
+
+func (e *abortedError) Error() string {
+	if e.message == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + ": " + e.message
+}
+
+func (e *abortedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestReason_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason Reason
+		want   string
+	}{
+		{
+			name:   "code only",
+			reason: Reason{Code: ReasonCanceled},
+			want:   "canceled",
+		}, {
+			name:   "code and message",
+			reason: Reason{Code: ReasonAborted, Message: "user pressed stop"},
+			want:   "aborted: user pressed stop",
+		}, {
+			name:   "custom code",
+			reason: Reason{Code: "quota-exceeded"},
+			want:   "quota-exceeded",
+		}, {
+			name:   "empty code",
+			reason: Reason{Message: "oops"},
+			want:   "io-error: oops",
+		}, {
+			name:   "invalid code",
+			reason: Reason{Code: "bad code:"},
+			want:   "io-error",
+		}, {
+			name:   "message with newlines and invalid characters",
+			reason: Reason{Code: ReasonIOError, Message: "read failed\n\tat \"line\" 5 <eof>"},
+			want:   "io-error: read failed at line 5 eof",
+		}, {
+			name:   "message that sanitizes to nothing",
+			reason: Reason{Code: ReasonIOError, Message: "\n\t\"\""},
+			want:   "io-error",
+		}, {
+			name:   "long message is truncated",
+			reason: Reason{Code: ReasonIOError, Message: strings.Repeat("a", 1000)},
+			want:   "io-error: " + strings.Repeat("a", maxReasonMessage),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.reason.String())
+		})
+	}
+}
+
+func TestParseReason(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Reason
+	}{
+		{in: "canceled", want: Reason{Code: ReasonCanceled}},
+		{in: " Timeout ", want: Reason{Code: ReasonTimeout}},
+		{in: "aborted: user pressed stop", want: Reason{Code: ReasonAborted, Message: "user pressed stop"}},
+		{in: "io-error: a: b", want: Reason{Code: ReasonIOError, Message: "a: b"}},
+		{in: "quota-exceeded", want: Reason{Code: "quota-exceeded"}},
+		{in: "unexpected EOF", want: Reason{Message: "unexpected EOF"}},
+		{in: "context canceled", want: Reason{Message: "context canceled"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseReason(tt.in))
+		})
+	}
+}
+
+func TestDefaultFinalReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ReasonCode
+	}{
+		{err: context.Canceled, want: ReasonCanceled},
+		{err: fmt.Errorf("wrapped: %w", context.Canceled), want: ReasonCanceled},
+		{err: context.DeadlineExceeded, want: ReasonTimeout},
+		{err: os.ErrDeadlineExceeded, want: ReasonTimeout},
+		{err: &abortedError{err: errors.New("stop")}, want: ReasonAborted},
+		{err: io.ErrUnexpectedEOF, want: ReasonIOError},
+		{err: errors.New("open /secret/path: permission denied"), want: ReasonIOError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, Reason{Code: tt.want}, DefaultFinalReason(tt.err))
+		})
+	}
+}
+
+func TestWithFinalReason(t *testing.T) {
+	p, err := New(
+		ID("123"),
+		Reader(&faultyReader{
+			Reader: bytes.NewReader([]byte("HelloWorld")),
+			when:   3,
+		}),
+		WithEncoding(EncodingIdentity),
+		WithFinalReason(func(error) Reason {
+			return Reason{Code: "quota", Message: "over\nlimit"}
+		}),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	got, err := p.Next(context.Background(), in)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.NotNil(t, got)
+	assert.Contains(t, got.Headers, "stream-final-packet: quota: over limit")
+
+	var assembler Assembler
+	require.NoError(t, assembler.ProcessWRP(context.Background(), *got))
+
+	buf, err := io.ReadAll(&assembler)
+	assert.Equal(t, "Hel", string(buf))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	var streamErr *StreamError
+	require.ErrorAs(t, err, &streamErr)
+	assert.Equal(t, Reason{Code: "quota", Message: "over limit"}, streamErr.Reason)
+}
+
+func TestStreamError(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  Reason
+		want    string
+		matches []error
+		not     []error
+	}{
+		{
+			name:    "canceled",
+			reason:  Reason{Code: ReasonCanceled},
+			want:    "unexpected EOF: canceled",
+			matches: []error{io.ErrUnexpectedEOF, ErrStreamCanceled},
+			not:     []error{ErrStreamTimeout, ErrStreamIOError, ErrStreamAborted, io.EOF},
+		}, {
+			name:    "timeout",
+			reason:  Reason{Code: ReasonTimeout},
+			want:    "unexpected EOF: timeout",
+			matches: []error{io.ErrUnexpectedEOF, ErrStreamTimeout},
+			not:     []error{ErrStreamCanceled},
+		}, {
+			name:    "io-error",
+			reason:  Reason{Code: ReasonIOError, Message: "disk"},
+			want:    "unexpected EOF: io-error: disk",
+			matches: []error{io.ErrUnexpectedEOF, ErrStreamIOError},
+		}, {
+			name:    "aborted",
+			reason:  Reason{Code: ReasonAborted},
+			want:    "unexpected EOF: aborted",
+			matches: []error{io.ErrUnexpectedEOF, ErrStreamAborted},
+		}, {
+			name:    "legacy reason",
+			reason:  Reason{Message: "unexpected EOF"},
+			want:    "unexpected EOF: unexpected EOF",
+			matches: []error{io.ErrUnexpectedEOF},
+			not:     []error{ErrStreamCanceled, ErrStreamTimeout, ErrStreamIOError, ErrStreamAborted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &StreamError{Reason: tt.reason}
+			assert.Equal(t, tt.want, err.Error())
+			for _, target := range tt.matches {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range tt.not {
+				assert.NotErrorIs(t, err, target)
+			}
+		})
+	}
+}
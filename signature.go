@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const (
+	// SignatureEd25519 is the algorithm name for Ed25519 signatures.
+	SignatureEd25519 = "ed25519"
+
+	// SignatureHMACSHA256 is the algorithm name for HMAC-SHA256 signatures
+	// for deployments that use a shared secret.
+	SignatureHMACSHA256 = "hmac-sha256"
+)
+
+// Signer signs packets produced by the Packetizer.  The signature covers the
+// SSP headers (including the packet number) and the payload as sent.
+type Signer interface {
+	// Algorithm returns the name of the signature algorithm.
+	Algorithm() string
+
+	// Sign returns the signature of the data.
+	Sign(data []byte) ([]byte, error)
+}
+
+// Verifier verifies the signatures of packets received by the Assembler.
+type Verifier interface {
+	// Verify returns nil if the signature of the data is valid for the
+	// algorithm, or an error otherwise.
+	Verify(algorithm string, data, signature []byte) error
+}
+
+// Ed25519Signer returns a Signer that signs packets with the private key.
+func Ed25519Signer(key ed25519.PrivateKey) Signer {
+	return ed25519Signer{key: key}
+}
+
+// Ed25519Verifier returns a Verifier that verifies Ed25519 signatures with the
+// public key.
+func Ed25519Verifier(key ed25519.PublicKey) Verifier {
+	return ed25519Verifier{key: key}
+}
+
+// HMACSigner returns a Signer that signs packets with HMAC-SHA256 using the
+// shared secret.
+func HMACSigner(secret []byte) Signer {
+	return hmacSHA256{secret: secret}
+}
+
+// HMACVerifier returns a Verifier that verifies HMAC-SHA256 signatures with the
+// shared secret.
+func HMACVerifier(secret []byte) Verifier {
+	return hmacSHA256{secret: secret}
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (s ed25519Signer) Algorithm() string {
+	return SignatureEd25519
+}
+
+func (s ed25519Signer) Sign(data []byte) ([]byte, error) {
+	if len(s.key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+
+	return ed25519.Sign(s.key, data), nil
+}
+
+type ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+func (v ed25519Verifier) Verify(algorithm string, data, signature []byte) error {
+	if algorithm != SignatureEd25519 {
+		return errors.New("unsupported algorithm " + algorithm)
+	}
+
+	if len(v.key) != ed25519.PublicKeySize || !ed25519.Verify(v.key, data, signature) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+type hmacSHA256 struct {
+	secret []byte
+}
+
+func (h hmacSHA256) Algorithm() string {
+	return SignatureHMACSHA256
+}
+
+func (h hmacSHA256) Sign(data []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.secret)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (h hmacSHA256) Verify(algorithm string, data, signature []byte) error {
+	if algorithm != SignatureHMACSHA256 {
+		return errors.New("unsupported algorithm " + algorithm)
+	}
+
+	want, _ := h.Sign(data)
+	if !hmac.Equal(want, signature) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// signingInput returns the data covered by the signature: each SSP header
+// other than the signature in canonical form followed by a newline, an empty
+// line, then the payload.
+func (ssm *simpleStreamingMessage) signingInput() []byte {
+	unsigned := *ssm
+	unsigned.StreamSignature = ""
+
+	var buf bytes.Buffer
+	for _, header := range unsigned.headers() {
+		buf.WriteString(header)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	buf.Write(ssm.Payload)
+
+	return buf.Bytes()
+}
+
+// sign sets the stream-signature of the message to "<algorithm>;<signature>"
+// where the signature is standard base64 encoded.
+func (ssm *simpleStreamingMessage) sign(signer Signer) error {
+	sig, err := signer.Sign(ssm.signingInput())
+	if err != nil {
+		return err
+	}
+
+	ssm.StreamSignature = signer.Algorithm() + ";" + base64.StdEncoding.EncodeToString(sig)
+	return nil
+}
+
+// verify checks the stream-signature of the message.
+func (ssm *simpleStreamingMessage) verify(verifier Verifier) error {
+	if ssm.StreamSignature == "" {
+		return &invalidSignature{number: ssm.StreamPacketNumber, reason: "missing signature"}
+	}
+
+	algorithm, encoded, found := strings.Cut(ssm.StreamSignature, ";")
+	if !found {
+		return &invalidSignature{number: ssm.StreamPacketNumber, reason: "malformed signature"}
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return &invalidSignature{number: ssm.StreamPacketNumber, reason: "malformed signature"}
+	}
+
+	err = verifier.Verify(strings.ToLower(strings.TrimSpace(algorithm)), ssm.signingInput(), sig)
+	if err != nil {
+		return &invalidSignature{number: ssm.StreamPacketNumber, reason: err.Error()}
+	}
+
+	return nil
+}
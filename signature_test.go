@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+type failingSigner struct{}
+
+func (failingSigner) Algorithm() string           { return "failing" }
+func (failingSigner) Sign([]byte) ([]byte, error) { return nil, errors.New("no signing key") }
+
+func TestSignature(t *testing.T) {
+	input := "Hello, World!"
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPublic, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	secret := []byte("shared secret")
+
+	tests := []struct {
+		name     string
+		signer   Signer
+		verifier Verifier
+		modify   func([]wrp.Message)
+		err      error
+	}{
+		{
+			name:     "ed25519",
+			signer:   Ed25519Signer(private),
+			verifier: Ed25519Verifier(public),
+		}, {
+			name:     "hmac-sha256",
+			signer:   HMACSigner(secret),
+			verifier: HMACVerifier(secret),
+		}, {
+			name:     "wrong public key",
+			signer:   Ed25519Signer(private),
+			verifier: Ed25519Verifier(otherPublic),
+			err:      ErrInvalidSignature,
+		}, {
+			name:     "wrong algorithm",
+			signer:   HMACSigner(secret),
+			verifier: Ed25519Verifier(public),
+			err:      ErrInvalidSignature,
+		}, {
+			name:     "wrong secret",
+			signer:   HMACSigner(secret),
+			verifier: HMACVerifier([]byte("other secret")),
+			err:      ErrInvalidSignature,
+		}, {
+			name:     "unsigned packet",
+			verifier: HMACVerifier(secret),
+			err:      ErrInvalidSignature,
+		}, {
+			name:     "rewritten payload",
+			signer:   Ed25519Signer(private),
+			verifier: Ed25519Verifier(public),
+			modify: func(msgs []wrp.Message) {
+				msgs[0].Payload = []byte("Jello")
+			},
+			err: ErrInvalidSignature,
+		}, {
+			name:     "rewritten packet number",
+			signer:   Ed25519Signer(private),
+			verifier: Ed25519Verifier(public),
+			modify: func(msgs []wrp.Message) {
+				for i, h := range msgs[0].Headers {
+					if strings.HasPrefix(h, stream_packet_number) {
+						msgs[0].Headers[i] = "stream-packet-number: 7"
+					}
+				}
+			},
+			err: ErrInvalidSignature,
+		}, {
+			name:     "malformed signature",
+			signer:   HMACSigner(secret),
+			verifier: HMACVerifier(secret),
+			modify: func(msgs []wrp.Message) {
+				for i, h := range msgs[0].Headers {
+					if strings.HasPrefix(h, stream_signature) {
+						msgs[0].Headers[i] = "stream-signature: hmac-sha256"
+					}
+				}
+			},
+			err: ErrInvalidSignature,
+		}, {
+			name:     "signature not base64",
+			signer:   HMACSigner(secret),
+			verifier: HMACVerifier(secret),
+			modify: func(msgs []wrp.Message) {
+				for i, h := range msgs[0].Headers {
+					if strings.HasPrefix(h, stream_signature) {
+						msgs[0].Headers[i] = "stream-signature: hmac-sha256;!!!"
+					}
+				}
+			},
+			err: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithEncoding(EncodingIdentity)}
+			if tt.signer != nil {
+				opts = append(opts, WithSigner(tt.signer))
+			}
+			msgs := packetize(t, input, opts...)
+			if tt.modify != nil {
+				tt.modify(msgs)
+			}
+
+			assembler := Assembler{Verifier: tt.verifier}
+			var errs []error
+			for _, msg := range msgs {
+				errs = append(errs, assembler.ProcessWRP(context.Background(), msg))
+			}
+			_ = assembler.Close()
+
+			if tt.err != nil {
+				assert.ErrorIs(t, errors.Join(errs...), tt.err)
+				return
+			}
+
+			assert.NoError(t, errors.Join(errs...))
+			got, err := io.ReadAll(&assembler)
+			assert.NoError(t, err)
+			assert.Equal(t, input, string(got))
+		})
+	}
+}
+
+func TestSignature_WithEncryption(t *testing.T) {
+	input := "Hello, World!"
+	secret := []byte("shared secret")
+
+	msgs := packetize(t, input,
+		WithEncoding(EncodingIdentity),
+		WithSigner(HMACSigner(secret)),
+		WithEncryption("key-1", testKeys),
+	)
+
+	assembler := Assembler{
+		Keys:     testKeys,
+		Verifier: HMACVerifier(secret),
+	}
+	for _, msg := range msgs {
+		require.NoError(t, assembler.ProcessWRP(context.Background(), msg))
+	}
+
+	got, err := io.ReadAll(&assembler)
+	assert.NoError(t, err)
+	assert.Equal(t, input, string(got))
+}
+
+func TestSignature_SignerError(t *testing.T) {
+	p, err := New(
+		ID("signed"),
+		Reader(strings.NewReader("Hello")),
+		WithSigner(failingSigner{}),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	got, err := p.Next(context.Background(), in)
+	assert.Nil(t, got)
+	assert.Error(t, err)
+
+	// The error is sticky.
+	got, err = p.Next(context.Background(), in)
+	assert.Nil(t, got)
+	assert.Error(t, err)
+}
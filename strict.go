@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+// checkProtocol checks the packet against the packets already received for the
+// rules enforced when Strict is set.  Must be called with the lock held.
+func (a *Assembler) checkProtocol(ssp *simpleStreamingMessage) error {
+	if !a.Strict {
+		return nil
+	}
+
+	number := ssp.StreamPacketNumber
+
+	if a.finalSeen {
+		if ssp.StreamFinalPacket != "" && number != a.finalNumber {
+			return &ProtocolViolationError{
+				Violation:    ErrMultipleFinalPackets,
+				PacketNumber: number,
+				Conflict:     a.finalNumber,
+			}
+		}
+
+		if number > a.finalNumber {
+			return &ProtocolViolationError{
+				Violation:    ErrPacketAfterFinal,
+				PacketNumber: number,
+				Conflict:     a.finalNumber,
+			}
+		}
+	}
+
+	if ssp.StreamFinalPacket != "" {
+		after := int64(-1)
+		for num := range a.packets {
+			if num > number && (after < 0 || num < after) {
+				after = num
+			}
+		}
+		if after >= 0 {
+			return &ProtocolViolationError{
+				Violation:    ErrPacketAfterFinal,
+				PacketNumber: after,
+				Conflict:     number,
+			}
+		}
+	}
+
+	// The estimate may only grow from one packet to the next.  Comparing with
+	// a single earlier estimate catches a decrease without false positives,
+	// whichever order the packets arrive in.
+	if length := ssp.StreamEstimatedLength; length > 0 && a.estimated.length > 0 {
+		if (number > a.estimated.number && length < a.estimated.length) ||
+			(number < a.estimated.number && length > a.estimated.length) {
+			return &ProtocolViolationError{
+				Violation:    ErrEstimatedLengthDecreased,
+				PacketNumber: number,
+				Conflict:     a.estimated.number,
+			}
+		}
+	}
+
+	return nil
+}
+
+// track records the final packet number and the estimated length of the
+// highest numbered packet received with one, for checkProtocol.  Must be
+// called with the lock held.
+func (a *Assembler) track(ssp *simpleStreamingMessage) {
+	if ssp.StreamFinalPacket != "" && !a.finalSeen {
+		a.finalSeen = true
+		a.finalNumber = ssp.StreamPacketNumber
+	}
+
+	if ssp.StreamEstimatedLength > 0 &&
+		(a.estimated.length == 0 || ssp.StreamPacketNumber > a.estimated.number) {
+		a.estimated.length = ssp.StreamEstimatedLength
+		a.estimated.number = ssp.StreamPacketNumber
+	}
+}
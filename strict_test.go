@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestAssembler_Strict(t *testing.T) {
+	packet := func(n int, headers ...string) wrp.Message {
+		return wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers: append([]string{
+				"stream-id: 1",
+				"stream-packet-number: " + strconv.Itoa(n),
+			}, headers...),
+			Payload: []byte(strconv.Itoa(n)),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		packets []wrp.Message
+		lenient string
+		want    *ProtocolViolationError
+	}{
+		{
+			name: "valid stream",
+			packets: []wrp.Message{
+				packet(1, "stream-estimated-total-length: 3"),
+				packet(0, "stream-estimated-total-length: 3"),
+				packet(2, "stream-final-packet: eof"),
+			},
+			lenient: "012",
+		}, {
+			name: "multiple final packets",
+			packets: []wrp.Message{
+				packet(0),
+				packet(2, "stream-final-packet: eof"),
+				packet(1, "stream-final-packet: eof"),
+			},
+			lenient: "01",
+			want: &ProtocolViolationError{
+				Violation:    ErrMultipleFinalPackets,
+				PacketNumber: 1,
+				Conflict:     2,
+			},
+		}, {
+			name: "packet after the final packet",
+			packets: []wrp.Message{
+				packet(1, "stream-final-packet: eof"),
+				packet(2),
+				packet(0),
+			},
+			lenient: "01",
+			want: &ProtocolViolationError{
+				Violation:    ErrPacketAfterFinal,
+				PacketNumber: 2,
+				Conflict:     1,
+			},
+		}, {
+			name: "final packet before a later packet",
+			packets: []wrp.Message{
+				packet(3),
+				packet(2),
+				packet(1, "stream-final-packet: eof"),
+				packet(0),
+			},
+			lenient: "01",
+			want: &ProtocolViolationError{
+				Violation:    ErrPacketAfterFinal,
+				PacketNumber: 2,
+				Conflict:     1,
+			},
+		}, {
+			name: "estimated length grows",
+			packets: []wrp.Message{
+				packet(2, "stream-estimated-total-length: 5", "stream-final-packet: eof"),
+				packet(0, "stream-estimated-total-length: 3"),
+				packet(1, "stream-estimated-total-length: 4"),
+			},
+			lenient: "012",
+		}, {
+			name: "estimated length decreased",
+			packets: []wrp.Message{
+				packet(0, "stream-estimated-total-length: 4"),
+				packet(1),
+				packet(2, "stream-estimated-total-length: 3", "stream-final-packet: eof"),
+			},
+			lenient: "012",
+			want: &ProtocolViolationError{
+				Violation:    ErrEstimatedLengthDecreased,
+				PacketNumber: 2,
+				Conflict:     0,
+			},
+		}, {
+			name: "estimated length decreased out of order",
+			packets: []wrp.Message{
+				packet(2, "stream-estimated-total-length: 3", "stream-final-packet: eof"),
+				packet(0, "stream-estimated-total-length: 4"),
+				packet(1),
+			},
+			lenient: "012",
+			want: &ProtocolViolationError{
+				Violation:    ErrEstimatedLengthDecreased,
+				PacketNumber: 0,
+				Conflict:     2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("lenient", func(t *testing.T) {
+				var assembler Assembler
+				for _, msg := range tt.packets {
+					require.NoError(t, assembler.ProcessWRP(context.Background(), msg))
+				}
+
+				got, err := io.ReadAll(&assembler)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.lenient, string(got))
+			})
+
+			t.Run("strict", func(t *testing.T) {
+				assembler := Assembler{Strict: true}
+
+				var err error
+				for _, msg := range tt.packets {
+					if err = assembler.ProcessWRP(context.Background(), msg); err != nil {
+						break
+					}
+				}
+
+				if tt.want == nil {
+					require.NoError(t, err)
+					got, err := io.ReadAll(&assembler)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.lenient, string(got))
+					return
+				}
+
+				var violation *ProtocolViolationError
+				require.ErrorAs(t, err, &violation)
+				assert.Equal(t, tt.want, violation)
+				assert.ErrorIs(t, err, ErrProtocolViolation)
+				assert.ErrorIs(t, err, tt.want.Violation)
+
+				// The stream ends with the violation.
+				_, err = io.ReadAll(&assembler)
+				assert.ErrorIs(t, err, tt.want.Violation)
+				assert.ErrorIs(t, assembler.ProcessWRP(context.Background(), packet(0)), ErrClosed)
+			})
+		})
+	}
+}
+
+func TestAssembler_StrictRefreshEstimatedLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "HelloWorld")
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	packetizer, err := New(
+		ID("123"),
+		Reader(f),
+		MaxPacketSize(5),
+		RefreshEstimatedLength(true),
+	)
+	require.NoError(t, err)
+
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	// The estimate grows as the file grows while it is sent.
+	assembler := Assembler{Strict: true}
+	var n int
+	for packet, err := range packetizer.Packets(context.Background(), in) {
+		require.NoError(t, err)
+		require.NoError(t, assembler.ProcessWRP(context.Background(), *packet))
+		if n == 0 {
+			appendFile(t, path, "Again")
+		}
+		n++
+	}
+
+	got, err := io.ReadAll(&assembler)
+	require.NoError(t, err)
+	assert.Equal(t, "HelloWorldAgain", string(got))
+}
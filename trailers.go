@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// validString matches the <string> grammar in protocol.md.
+var validString = regexp.MustCompile(`^[A-Za-z0-9 !#$&'()*+,\-./:;=?@\[\\\]_|~]*$`)
+
+// validateTrailers checks the trailer names are identifiers and the values are
+// strings as defined by protocol.md.
+func validateTrailers(trailers map[string]string) error {
+	for name, value := range trailers {
+		if !validID.MatchString(name) {
+			return fmt.Errorf("%w: trailer name %q contains invalid characters", ErrInvalidInput, name)
+		}
+		if !validString.MatchString(value) {
+			return fmt.Errorf("%w: trailer %q value contains invalid characters", ErrInvalidInput, name)
+		}
+	}
+
+	return nil
+}
+
+// normalizeTrailers returns the trailers with lowercase names and values
+// without surrounding whitespace, matching how they are received.
+func normalizeTrailers(trailers map[string]string) (map[string]string, error) {
+	if err := validateTrailers(trailers); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(trailers))
+	for name, value := range trailers {
+		name = strings.ToLower(name)
+		if _, found := out[name]; found {
+			return nil, fmt.Errorf("%w: trailer name %q is duplicated", ErrInvalidInput, name)
+		}
+		out[name] = strings.TrimSpace(value)
+	}
+
+	return out, nil
+}
+
+// Trailers returns the trailers sent with the final packet of the stream.  The
+// names are lowercase.  The trailers are available once the final packet has
+// been read, which is when Read returns io.EOF.  Until then, ErrNotAvailable is
+// returned.
+func (a *Assembler) Trailers() (map[string]string, error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	if a.trailers == nil {
+		return nil, ErrNotAvailable
+	}
+
+	return maps.Clone(a.trailers), nil
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestWithTrailers(t *testing.T) {
+	errTrailers := errors.New("hash failed")
+
+	tests := []struct {
+		name     string
+		trailers func() (map[string]string, error)
+		headers  []string
+		want     map[string]string
+		err      error
+		finalErr error
+	}{
+		{
+			name: "trailers are sent with the final packet",
+			trailers: func() (map[string]string, error) {
+				return map[string]string{
+					"Records": " 2 ",
+					"sha256":  "abc123",
+				}, nil
+			},
+			headers: []string{
+				"stream-trailer-records: 2",
+				"stream-trailer-sha256: abc123",
+			},
+			want: map[string]string{
+				"records": "2",
+				"sha256":  "abc123",
+			},
+			err: io.EOF,
+		}, {
+			name: "no trailers",
+			trailers: func() (map[string]string, error) {
+				return nil, nil
+			},
+			want: map[string]string{},
+			err:  io.EOF,
+		}, {
+			name: "the function fails",
+			trailers: func() (map[string]string, error) {
+				return nil, errTrailers
+			},
+			headers:  []string{"stream-final-packet: io-error"},
+			want:     map[string]string{},
+			err:      errTrailers,
+			finalErr: ErrStreamIOError,
+		}, {
+			name: "invalid name",
+			trailers: func() (map[string]string, error) {
+				return map[string]string{"record count": "2"}, nil
+			},
+			err:      ErrInvalidInput,
+			want:     map[string]string{},
+			finalErr: ErrStreamIOError,
+		}, {
+			name: "invalid value",
+			trailers: func() (map[string]string, error) {
+				return map[string]string{"records": "2\n"}, nil
+			},
+			err:      ErrInvalidInput,
+			want:     map[string]string{},
+			finalErr: ErrStreamIOError,
+		}, {
+			name: "duplicate names",
+			trailers: func() (map[string]string, error) {
+				return map[string]string{"records": "2", "Records": "3"}, nil
+			},
+			err:      ErrInvalidInput,
+			want:     map[string]string{},
+			finalErr: ErrStreamIOError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			packetizer, err := New(
+				ID("123"),
+				Reader(strings.NewReader("HelloWorld")),
+				MaxPacketSize(5),
+				WithEncoding(EncodingIdentity),
+				WithTrailers(func() (map[string]string, error) {
+					calls++
+					return tt.trailers()
+				}),
+			)
+			require.NoError(t, err)
+
+			in := wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "mac:112233445566",
+				Destination: "event:test",
+			}
+
+			var assembler Assembler
+			var final *wrp.Message
+			for i := 0; ; i++ {
+				msg, err := packetizer.Next(context.Background(), in)
+				require.NotNil(t, msg)
+				require.NoError(t, assembler.ProcessWRP(context.Background(), *msg))
+
+				if err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					final = msg
+					break
+				}
+
+				// Trailers are not sent until the stream ends.
+				for _, header := range msg.Headers {
+					assert.False(t, strings.HasPrefix(header, "stream-trailer-"), "packet "+strconv.Itoa(i))
+				}
+			}
+			assert.Equal(t, 1, calls)
+
+			for _, header := range tt.headers {
+				assert.Contains(t, final.Headers, header)
+			}
+
+			got, err := io.ReadAll(&assembler)
+			assert.Equal(t, "HelloWorld", string(got))
+			if tt.finalErr != nil {
+				assert.ErrorIs(t, err, tt.finalErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			trailers, err := assembler.Trailers()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, trailers)
+		})
+	}
+}
+
+func TestWithTrailers_Abort(t *testing.T) {
+	var calls int
+	packetizer, err := New(
+		ID("123"),
+		Reader(strings.NewReader("HelloWorld")),
+		WithTrailers(func() (map[string]string, error) {
+			calls++
+			return map[string]string{"records": "1"}, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	msg, err := packetizer.Abort(context.Background(), wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}, "")
+	assert.ErrorIs(t, err, ErrAborted)
+	require.NotNil(t, msg)
+	assert.NotContains(t, msg.Headers, "stream-trailer-records: 1")
+	assert.Zero(t, calls)
+}
+
+func TestAssembler_Trailers(t *testing.T) {
+	var assembler Assembler
+
+	_, err := assembler.Trailers()
+	assert.ErrorIs(t, err, ErrNotAvailable)
+
+	for i, payload := range []string{"Hello", "World"} {
+		headers := []string{
+			"stream-id: 1",
+			"stream-packet-number: " + strconv.Itoa(i),
+		}
+		if i == 1 {
+			headers = append(headers, "stream-final-packet: eof", "Stream-Trailer-Records : 2 ")
+		}
+		require.NoError(t, assembler.ProcessWRP(context.Background(), wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:test",
+			Headers:     headers,
+			Payload:     []byte(payload),
+		}))
+	}
+
+	// The final packet has arrived but has not been read.
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(&assembler, buf)
+	require.NoError(t, err)
+	_, err = assembler.Trailers()
+	assert.ErrorIs(t, err, ErrNotAvailable)
+
+	got, err := io.ReadAll(&assembler)
+	assert.NoError(t, err)
+	assert.Equal(t, "World", string(got))
+
+	trailers, err := assembler.Trailers()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"records": "2"}, trailers)
+
+	// The map returned is a copy.
+	trailers["records"] = "3"
+	trailers, _ = assembler.Trailers()
+	assert.Equal(t, "2", trailers["records"])
+}
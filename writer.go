@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+// PacketWriter is the push based counterpart to the Packetizer.  Data written
+// to the PacketWriter is buffered until a full packet is available, then the
+// packet is handed to the send function as a fully formed WRP message.
+//
+// The PacketWriter implements the io.WriteCloser interface.  Close must be
+// called to emit the final packet of the stream.
+//
+// Similar to io.Writer, the PacketWriter is not safe for concurrent use.
+type PacketWriter struct {
+	ctx      context.Context
+	msg      wrp.Message
+	send     func(context.Context, wrp.Message) error
+	packer   *Packetizer
+	buf      writerBuffer
+	closed   bool
+	err      error
+	closeErr error
+}
+
+var _ io.WriteCloser = (*PacketWriter)(nil)
+
+// NewWriter creates a new PacketWriter.  The msg is used as the template for
+// every packet produced, and send is called once per packet in order.  The
+// ctx is passed to the send function and is checked before each packet is
+// produced.
+//
+// The options are the same as for New, except the Reader option is ignored
+// since the data is provided via Write.  MaxLatency and InterruptibleReads are
+// rejected with ErrInvalidInput, since packets are only produced by Write and
+// Close, never while waiting for more data.
+func NewWriter(ctx context.Context, msg wrp.Message, send func(context.Context, wrp.Message) error, opts ...Option) (*PacketWriter, error) {
+	if send == nil {
+		return nil, fmt.Errorf("%w: send must not be nil", ErrInvalidInput)
+	}
+
+	w := PacketWriter{
+		ctx:  ctx,
+		msg:  msg,
+		send: send,
+	}
+
+	opts = append(opts, Reader(&w.buf))
+
+	var err error
+	w.packer, err = New(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.packer.maxLatency > 0 || w.packer.interruptible {
+		return nil, fmt.Errorf("%w: MaxLatency and InterruptibleReads are not supported by the PacketWriter", ErrInvalidInput)
+	}
+
+	return &w, nil
+}
+
+// Write implements the io.Writer interface.  Each time enough data has been
+// written to fill a packet, the packet is sent before Write returns.  If the
+// send fails, the error is returned and all subsequent calls to Write return
+// the same error.
+func (w *PacketWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		room := w.packer.maxPacketSize - w.buf.Len()
+		chunk := min(room, len(p))
+
+		w.buf.Write(p[:chunk])
+		p = p[chunk:]
+		n += chunk
+
+		if w.buf.Len() < w.packer.maxPacketSize {
+			continue
+		}
+
+		if err := w.flush(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Close implements the io.Closer interface.  Any buffered data is sent along
+// with the stream-final-packet marker indicating the stream ended normally.
+func (w *PacketWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError sends any buffered data in the final packet of the stream
+// with the aborted reason.  The err is passed to the WithFinalReason function
+// if one is set.  If err is nil, CloseWithError behaves like Close.
+//
+// Only the first call to Close or CloseWithError has any effect; later calls
+// return the result of the first call.
+func (w *PacketWriter) CloseWithError(err error) error {
+	if w.closed {
+		return w.closeErr
+	}
+	w.closed = true
+
+	if err == nil {
+		err = io.EOF
+	} else {
+		err = &abortedError{err: err}
+	}
+	w.buf.err = err
+
+	if w.err != nil {
+		w.closeErr = w.err
+		return w.closeErr
+	}
+
+	w.closeErr = w.flush()
+	return w.closeErr
+}
+
+// flush produces the next packet from the buffered data and sends it.
+func (w *PacketWriter) flush() error {
+	msg, err := w.packer.Next(w.ctx, w.msg)
+	if msg != nil {
+		if sendErr := w.send(w.ctx, *msg); sendErr != nil {
+			w.err = sendErr
+			return sendErr
+		}
+	}
+
+	// The outcome of the final packet is expected and not a failure.
+	if err != nil && !errors.Is(err, w.buf.err) {
+		w.err = err
+		return err
+	}
+
+	return nil
+}
+
+// writerBuffer is the reader the Packetizer pulls from.  Once the buffered
+// data is consumed, the err is returned if it has been set.
+type writerBuffer struct {
+	bytes.Buffer
+	err error
+}
+
+func (b *writerBuffer) Read(p []byte) (int, error) {
+	if b.Len() == 0 && b.err != nil {
+		return 0, b.err
+	}
+
+	return b.Buffer.Read(p)
+}
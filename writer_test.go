@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2025 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package wrpssp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xmidt-org/wrp-go/v5"
+)
+
+func TestNewWriter(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+	send := func(context.Context, wrp.Message) error { return nil }
+
+	w, err := NewWriter(context.Background(), in, nil, ID("123"))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, w)
+
+	w, err = NewWriter(context.Background(), in, send)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, w)
+
+	w, err = NewWriter(context.Background(), in, send, ID("123"))
+	assert.NoError(t, err)
+	assert.NotNil(t, w)
+
+	// Reads are never waited on, so blocking reads are not supported.
+	w, err = NewWriter(context.Background(), in, send, ID("123"), InterruptibleReads(true))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Nil(t, w)
+}
+
+func TestPacketWriter_MaxLatency(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	var sent int
+	send := func(context.Context, wrp.Message) error {
+		sent++
+		return nil
+	}
+
+	w, err := NewWriter(context.Background(), in, send,
+		ID("writer"),
+		MaxPacketSize(5),
+		MaxLatency(time.Millisecond),
+	)
+	require.ErrorIs(t, err, ErrInvalidInput)
+	require.Nil(t, w)
+
+	// Without it, the data written is sent in full.
+	var assembler Assembler
+	w, err = NewWriter(context.Background(), in, func(ctx context.Context, msg wrp.Message) error {
+		sent++
+		return assembler.ProcessWRP(ctx, msg)
+	}, ID("writer"), MaxPacketSize(5))
+	require.NoError(t, err)
+
+	for _, s := range []string{"Hel", "lo, ", "World!"} {
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, w.Close())
+
+	got, err := io.ReadAll(&assembler)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, World!", string(got))
+	assert.Equal(t, 3, sent)
+}
+
+func TestPacketWriter(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+	errAbort := errors.New("user aborted")
+
+	tests := []struct {
+		name     string
+		writes   []string
+		closeErr error
+		packets  []string
+		final    string
+		want     string
+		finalErr error
+	}{
+		{
+			name:    "empty stream",
+			packets: []string{""},
+			final:   "stream-final-packet: eof",
+			want:    "",
+		}, {
+			name:    "partial final packet",
+			writes:  []string{"Hello", ", World!"},
+			packets: []string{"Hello", ", Wor", "ld!"},
+			final:   "stream-final-packet: eof",
+			want:    "Hello, World!",
+		}, {
+			name:    "exact multiple of the packet size",
+			writes:  []string{"ABCDEFGHIJ"},
+			packets: []string{"ABCDE", "FGHIJ", ""},
+			final:   "stream-final-packet: eof",
+			want:    "ABCDEFGHIJ",
+		}, {
+			name:     "closed with an error",
+			writes:   []string{"Hello", ", W"},
+			closeErr: errAbort,
+			packets:  []string{"Hello", ", W"},
+			final:    "stream-final-packet: aborted",
+			want:     "Hello, W",
+			finalErr: ErrStreamAborted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []wrp.Message
+			var assembler Assembler
+			send := func(ctx context.Context, msg wrp.Message) error {
+				sent = append(sent, msg)
+				return assembler.ProcessWRP(ctx, msg)
+			}
+
+			w, err := NewWriter(context.Background(), in, send,
+				ID("writer"),
+				MaxPacketSize(5),
+				WithEncoding(EncodingIdentity),
+			)
+			require.NoError(t, err)
+
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				require.NoError(t, err)
+				assert.Equal(t, len(s), n)
+			}
+
+			if tt.closeErr != nil {
+				assert.NoError(t, w.CloseWithError(tt.closeErr))
+			} else {
+				assert.NoError(t, w.Close())
+			}
+
+			require.Equal(t, len(tt.packets), len(sent))
+			for i, msg := range sent {
+				assert.Equal(t, tt.packets[i], string(msg.Payload))
+				if i == len(sent)-1 {
+					assert.Contains(t, msg.Headers, tt.final)
+				}
+			}
+
+			got, err := io.ReadAll(&assembler)
+			assert.Equal(t, tt.want, string(got))
+			if tt.finalErr != nil {
+				assert.ErrorIs(t, err, tt.finalErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Writing or closing again does not produce more packets.
+			n, err := w.Write([]byte("more"))
+			assert.ErrorIs(t, err, ErrClosed)
+			assert.Equal(t, 0, n)
+			assert.NoError(t, w.Close())
+			assert.Equal(t, len(tt.packets), len(sent))
+		})
+	}
+}
+
+func TestPacketWriter_SendError(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+	errSend := errors.New("send failed")
+
+	var calls int
+	send := func(context.Context, wrp.Message) error {
+		calls++
+		return errSend
+	}
+
+	w, err := NewWriter(context.Background(), in, send,
+		ID("writer"),
+		MaxPacketSize(5),
+	)
+	require.NoError(t, err)
+
+	n, err := w.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = w.Write([]byte("defgh"))
+	assert.ErrorIs(t, err, errSend)
+	assert.Equal(t, 2, n)
+
+	n, err = w.Write([]byte("ijk"))
+	assert.ErrorIs(t, err, errSend)
+	assert.Equal(t, 0, n)
+
+	assert.ErrorIs(t, w.Close(), errSend)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPacketWriter_ContextCanceled(t *testing.T) {
+	in := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:test",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	send := func(context.Context, wrp.Message) error {
+		calls++
+		return nil
+	}
+
+	w, err := NewWriter(ctx, in, send, ID("writer"), MaxPacketSize(5))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("0123456789"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, w.Close(), context.Canceled)
+	assert.Equal(t, 0, calls)
+}